@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mohsen-farahani/gokitgen/pkg/generator/model"
+)
+
+// importer reads models from source, the argument following the kind of
+// source on the command line. skipped lists what could not be imported.
+type importer func(source string) (configs []*model.ModelConfig, skipped []string, err error)
+
+var importers = map[string]importer{
+	"go":      importGo,
+	"sql":     importSQL,
+	"sqlite":  model.ImportSQLite,
+	"openapi": model.ImportOpenAPI,
+	"proto":   model.ImportProto,
+}
+
+func runImport(args []string) int {
+	if len(args) == 0 || (importers[args[0]] == nil && args[0] != "json") {
+		printImportUsage()
+		return 2
+	}
+	kind, read := args[0], importers[args[0]]
+
+	fs := flag.NewFlagSet("import "+kind, flag.ContinueOnError)
+	var (
+		spec  = fs.String("spec", "", "write the imported model to this YAML or JSON spec file instead of generating code")
+		flags = addGenerateFlags(fs)
+	)
+	if kind == "json" {
+		name := fs.String("name", "", "model name of the payload, e.g. Order")
+		read = func(source string) ([]*model.ModelConfig, []string, error) {
+			return importJSON(source, *name)
+		}
+	}
+	fs.Usage = func() {
+		printImportUsage()
+		fs.PrintDefaults()
+	}
+	// Flags may come before or after the source.
+	var sources []string
+	for rest := args[1:]; ; rest = fs.Args()[1:] {
+		if err := fs.Parse(rest); err != nil {
+			if err == flag.ErrHelp {
+				return 0
+			}
+			return 2
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		sources = append(sources, fs.Arg(0))
+	}
+	if len(sources) != 1 {
+		fs.Usage()
+		return 2
+	}
+	opts, err := flags.options()
+	if err != nil {
+		return fail(err)
+	}
+
+	configs, skipped, err := read(sources[0])
+	if err != nil {
+		return fail(err)
+	}
+	for _, s := range skipped {
+		fmt.Printf("⏭️  Not imported: %s\n", s)
+	}
+	if len(configs) == 0 {
+		return fail(fmt.Errorf("no model found in %s", sources[0]))
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, config := range configs {
+		// The module of the output directory is the best guess, then the
+		// one the importer found next to the source. A database or
+		// operations the importer recognised are kept unless --database or
+		// --ops say otherwise.
+		imported, database, ops := config.ModulePath, config.Database, config.Operations
+		if err := flags.apply(config, func(string) bool { return true }); err != nil {
+			return fail(err)
+		}
+		if config.ModulePath == "" {
+			config.ModulePath = cmp.Or(moduleOf(config.OutputPath), imported)
+		}
+		if database != "" && !set["database"] {
+			config.Database = database
+		}
+		if len(ops) > 0 && !set["ops"] {
+			config.Operations = ops
+		}
+	}
+
+	// Relations may point at models generated before, so only the models
+	// themselves are checked.
+	project := &model.Project{Models: configs}
+	if err := project.ValidateModels(); err != nil {
+		return fail(err)
+	}
+
+	if kind == "json" && *spec == "" {
+		// Inferred models are a guess; have them confirmed one by one.
+		if configs, err = confirmImported(configs); err != nil {
+			if errors.Is(err, model.ErrAborted) {
+				fmt.Println("👋 Aborted, nothing was generated.")
+				return 1
+			}
+			return fail(err)
+		}
+		opts.confirm = model.ConfirmOverwrite
+	}
+
+	if *spec != "" {
+		if err := saveImported(*spec, configs); err != nil {
+			return fail(err)
+		}
+		for _, config := range configs {
+			fmt.Printf("📝 Imported %s with %d field(s) into %s\n", config.ModelName, len(config.Fields), *spec)
+		}
+		return 0
+	}
+	return generate(opts, configs...)
+}
+
+// saveImported writes a single model as a model spec and several as a
+// project spec sharing their settings.
+func saveImported(path string, configs []*model.ModelConfig) error {
+	if len(configs) == 1 {
+		return model.SaveSpec(path, configs[0])
+	}
+	first := configs[0]
+	project := &model.Project{
+		ModulePath:    first.ModulePath,
+		OutputPath:    first.OutputPath,
+		GenerateHTTP:  first.GenerateHTTP,
+		GenerategRPC:  first.GenerategRPC,
+		GenerateTests: first.GenerateTests,
+		DBManager:     first.DBManager,
+		Database:      first.Database,
+	}
+	for _, config := range configs {
+		m := *config
+		m.ModulePath, m.OutputPath, m.DBManager, m.Database = "", "", "", ""
+		m.GenerateHTTP, m.GenerategRPC, m.GenerateTests = false, false, false
+		project.Models = append(project.Models, &m)
+	}
+	return model.SaveProject(path, project)
+}
+
+// confirmImported opens the wizard for each model, prefilled with what was
+// imported. The answers shared by all models carry over to the next one,
+// and a renamed model is renamed in the relations to it.
+func confirmImported(configs []*model.ModelConfig) ([]*model.ModelConfig, error) {
+	for i, config := range configs {
+		if i > 0 {
+			prev := configs[i-1]
+			config.ModulePath, config.OutputPath, config.Database = prev.ModulePath, prev.OutputPath, prev.Database
+			config.GenerateHTTP, config.GenerategRPC, config.GenerateTests = prev.GenerateHTTP, prev.GenerategRPC, prev.GenerateTests
+		}
+		name := config.ModelName
+		confirmed, err := model.RunWizardWith(config)
+		if err != nil {
+			return nil, err
+		}
+		configs[i] = confirmed
+		if confirmed.ModelName == name {
+			continue
+		}
+		for _, other := range configs {
+			for j, f := range other.Fields {
+				if f.TypeIsRelation && f.Type == name {
+					other.Fields[j].Type = confirmed.ModelName
+				}
+			}
+		}
+	}
+	project := &model.Project{Models: configs}
+	return configs, project.Validate()
+}
+
+func printImportUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  gokitgen import go path/to/file.go:Type [flags]  Import a GORM struct
+  gokitgen import sql schema.sql [flags]           Import the tables of a MySQL, PostgreSQL or SQLite schema
+  gokitgen import sqlite app.db [flags]            Import the tables of a SQLite database
+  gokitgen import openapi api.yaml [flags]         Import the component schemas of an OpenAPI 3 document
+  gokitgen import proto order.proto [flags]        Import the messages of a protobuf file
+  gokitgen import json sample.json --name Order    Infer a model from a sample payload and confirm it in the wizard`)
+}
+
+// importGo reads "path/to/file.go:Type".
+func importGo(source string) ([]*model.ModelConfig, []string, error) {
+	path, typeName, ok := strings.Cut(source, ".go:")
+	if !ok || typeName == "" {
+		return nil, nil, fmt.Errorf("invalid source %q: expected path/to/file.go:Type", source)
+	}
+	config, skipped, err := model.ImportGoStruct(path+".go", typeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.ModulePath = moduleOf(filepath.Dir(path))
+	return []*model.ModelConfig{config}, skipped, nil
+}
+
+// importJSON reads a sample payload of the model name.
+func importJSON(source, name string) ([]*model.ModelConfig, []string, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	configs, skipped, err := model.ImportJSON(data, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return configs, skipped, nil
+}
+
+func importSQL(source string) ([]*model.ModelConfig, []string, error) {
+	script, err := os.ReadFile(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	return model.ImportSQL(string(script))
+}
+
+// moduleOf returns the module path declared in the go.mod of dir or of the
+// closest directory above it, or "" if there is none.
+func moduleOf(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if f, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+					return strings.Trim(strings.TrimSpace(path), `"`)
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-
-	"github.com/mohsen-farahani/gokitgen/pkg/generator/model"
 )
 
 var (
@@ -78,6 +76,42 @@ func (m mainModel) View() string {
 var docStyle = lipgloss.NewStyle().Margin(1, 2)
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+	runMenu()
+}
+
+func runCommand(name string, args []string) int {
+	switch name {
+	case "model":
+		return runModel(args)
+	case "import":
+		return runImport(args)
+	case "status":
+		return runStatus(args)
+	case "clean":
+		return runClean(args)
+	case "help", "-h", "--help":
+		printUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n", name)
+		printUsage()
+		return 2
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  gokitgen              Open the interactive menu
+  gokitgen model [...]  Generate a model (run "gokitgen model -h" for flags)
+  gokitgen import [...] Import a model from existing code (run "gokitgen import" for sources)
+  gokitgen status       Show which generated files were modified by hand
+  gokitgen clean Model  Remove the unmodified generated files of a model`)
+}
+
+func runMenu() {
 	items := []list.Item{
 		item{title: "🚀 Init Project", desc: "Initialize a new Go Kit project structure", command: "init"},
 		item{title: "📦 Generate Model", desc: "Generate model, service, API, tests, and more", command: "model"},
@@ -116,12 +150,7 @@ func main() {
 	case "init":
 		fmt.Println("Initializing project... (not implemented yet)")
 	case "model":
-		config := model.RunWizard()
-		if err := model.GenerateCode(config); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-		} else {
-			fmt.Println("✅ Model generated successfully!")
-		}
+		os.Exit(runWizard())
 	case "command":
 		fmt.Println("Command generator coming soon...")
 	}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mohsen-farahani/gokitgen/pkg/generator/model"
+)
+
+// stringList collects the values of a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runModel(args []string) int {
+	// Without flags keep the old behaviour and ask everything interactively.
+	if len(args) == 0 {
+		return runWizard()
+	}
+
+	fs := flag.NewFlagSet("model", flag.ContinueOnError)
+	var (
+		spec   = fs.String("f", "", "load the model from a YAML or JSON spec file")
+		name   = fs.String("name", "", "model name, e.g. Order")
+		flags  = addGenerateFlags(fs)
+		fields stringList
+		enums  stringList
+	)
+	fs.Var(&fields, "field", "field as Name:Type, repeatable (Type may be an enum, *Type for nullable, Ref:Model for a relation)")
+	fs.Var(&enums, "enum", "enum as Name=VALUE1,VALUE2, repeatable")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gokitgen model --name Order --module github.com/acme/orders [flags]")
+		fmt.Fprintln(fs.Output(), "       gokitgen model -f order.yaml [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	opts, err := flags.options()
+	if err != nil {
+		return fail(err)
+	}
+
+	project := &model.Project{Models: []*model.ModelConfig{{}}}
+	if *spec != "" {
+		// Validated below, once the flags are applied.
+		loaded, err := model.ReadProject(*spec)
+		if err != nil {
+			return fail(err)
+		}
+		project = loaded
+	}
+
+	// Flags given explicitly win over the spec file; defaults only fill in
+	// when there is no spec.
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	useFlag := func(name string) bool { return set[name] || *spec == "" }
+
+	multi := len(project.Models) > 1
+	if multi && (set["name"] || len(fields) > 0 || len(enums) > 0) {
+		return fail(fmt.Errorf("--name, --field and --enum cannot be used with a multi-model spec"))
+	}
+
+	for _, config := range project.Models {
+		if err := flags.apply(config, useFlag); err != nil {
+			return fail(err)
+		}
+	}
+
+	if multi {
+		if err := project.Validate(); err != nil {
+			return fail(err)
+		}
+		return generate(opts, project.Models...)
+	}
+
+	config := project.Models[0]
+	if useFlag("name") {
+		config.ModelName = *name
+	}
+
+	for _, e := range enums {
+		enum, err := model.ParseEnum(e)
+		if err != nil {
+			return fail(err)
+		}
+		config.Enums = append(config.Enums, enum)
+	}
+
+	for _, f := range fields {
+		field, err := model.ParseField(f, config.Enums)
+		if err != nil {
+			return fail(err)
+		}
+		config.Fields = append(config.Fields, field)
+	}
+
+	// A spec file is checked as a whole, relations included.
+	validate := config.Validate
+	if *spec != "" {
+		validate = project.Validate
+	}
+	if err := validate(); err != nil {
+		return fail(err)
+	}
+	return generate(opts, config)
+}
+
+// generateFlags are the flags of the commands that generate models.
+type generateFlags struct {
+	module, transport, ops, out, database, dbManager *string
+	tests, dryRun, force, skip, verify               *bool
+}
+
+func addGenerateFlags(fs *flag.FlagSet) *generateFlags {
+	return &generateFlags{
+		module:    fs.String("module", "", "Go module path used in generated imports"),
+		transport: fs.String("transport", "http", "comma separated transports: http, grpc"),
+		ops:       fs.String("ops", "all", "comma separated operations: create, get, list, update, patch, delete"),
+		tests:     fs.Bool("tests", false, "generate tests"),
+		out:       fs.String("out", "./", "output directory"),
+		dryRun:    fs.Bool("dry-run", false, "list the files that would be generated without writing them"),
+		force:     fs.Bool("force", false, "overwrite existing files that differ from the generated ones"),
+		skip:      fs.Bool("skip-existing", false, "keep existing files that differ and only write new ones"),
+		verify:    fs.Bool("verify", false, "type-check the generated code and stop before writing if it does not compile"),
+		database:  fs.String("database", model.DatabaseMySQL, "database dialect: mysql, postgres or sqlite"),
+		dbManager: fs.String("db-manager", "", "build repositories from this type instead of *gorm.DB, as import/path.Type[.Method] (Method returns the *gorm.DB, default DB)"),
+	}
+}
+
+// options returns how the flags want the output directory treated.
+func (f *generateFlags) options() (generateOptions, error) {
+	opts := generateOptions{dryRun: *f.dryRun, verify: *f.verify}
+	switch {
+	case *f.force && *f.skip:
+		return opts, fmt.Errorf("--force and --skip-existing cannot be used together")
+	case *f.force:
+		opts.overwrite = model.OverwriteForce
+	case *f.skip:
+		opts.overwrite = model.OverwriteSkip
+	}
+	return opts, nil
+}
+
+// apply sets the flags useFlag selects on config.
+func (f *generateFlags) apply(config *model.ModelConfig, useFlag func(name string) bool) error {
+	if useFlag("module") {
+		config.ModulePath = *f.module
+	}
+	if useFlag("tests") {
+		config.GenerateTests = *f.tests
+	}
+	if useFlag("out") {
+		config.OutputPath = *f.out
+	}
+	if useFlag("transport") {
+		var err error
+		if config.GenerateHTTP, config.GenerategRPC, err = parseTransports(*f.transport); err != nil {
+			return err
+		}
+	}
+	if useFlag("database") {
+		config.Database = strings.ToLower(*f.database)
+	}
+	if useFlag("db-manager") && *f.dbManager != "" {
+		config.DBManager = *f.dbManager
+	}
+	if useFlag("ops") {
+		var err error
+		if config.Operations, err = model.ParseOperations(*f.ops); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseTransports(s string) (httpOn, grpcOn bool, err error) {
+	for _, t := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "http":
+			httpOn = true
+		case "grpc":
+			grpcOn = true
+		case "", "none":
+		default:
+			return false, false, fmt.Errorf("unknown transport %q (expected http or grpc)", t)
+		}
+	}
+	return httpOn, grpcOn, nil
+}
+
+func runWizard() int {
+	config, err := model.RunWizard()
+	if errors.Is(err, model.ErrAborted) {
+		fmt.Println("👋 Aborted, nothing was generated.")
+		return 1
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return generate(generateOptions{confirm: model.ConfirmOverwrite}, config)
+}
+
+// generateOptions controls how generate treats the output directory.
+type generateOptions struct {
+	dryRun    bool
+	verify    bool
+	overwrite model.OverwritePolicy
+	// confirm asks about conflicts interactively; without it they are
+	// reported and nothing is written.
+	confirm func([]model.Conflict) (model.OverwritePolicy, error)
+}
+
+func generate(opts generateOptions, configs ...*model.ModelConfig) int {
+	if opts.verify {
+		if err := model.NewGenerator(model.DiskOutput{}).Verify(configs...); err != nil {
+			return fail(err)
+		}
+		fmt.Println("🔍 Generated code type-checks.")
+	}
+
+	if opts.dryRun {
+		out := &model.DryRunOutput{W: os.Stdout}
+		g := model.NewGenerator(out)
+		_, conflicts, err := g.Plan(configs...)
+		if err != nil {
+			return fail(err)
+		}
+		if len(conflicts) > 0 && opts.overwrite == model.OverwriteAsk {
+			printConflicts(conflicts)
+			fmt.Println()
+		}
+
+		// Show everything that would be written once the conflicts are
+		// resolved, unless they are explicitly skipped.
+		g.Overwrite = model.OverwriteForce
+		if opts.overwrite == model.OverwriteSkip {
+			g.Overwrite = model.OverwriteSkip
+		}
+		fmt.Println("🧪 Dry run — these files would be written:")
+		if err := g.Generate(configs...); err != nil {
+			return fail(err)
+		}
+		fmt.Printf("🧪 %d files, %d bytes in total. Nothing was written.\n", out.Files, out.Bytes)
+		return 0
+	}
+
+	g := model.NewGenerator(model.DiskOutput{})
+	g.Overwrite = opts.overwrite
+	g.Confirm = opts.confirm
+	err := g.Generate(configs...)
+	if errors.Is(err, model.ErrAborted) {
+		fmt.Println("👋 Aborted, nothing was generated.")
+		return 1
+	}
+	var conflict *model.ConflictError
+	if errors.As(err, &conflict) {
+		printConflicts(conflict.Conflicts)
+		fmt.Fprintln(os.Stderr, "❌ Nothing was generated. Use --force to overwrite or --skip-existing to keep these files.")
+		return 1
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("✅ Code generated successfully in %s\n", configs[0].OutputPath)
+	if len(configs) > 1 {
+		fmt.Printf("✅ %d models generated successfully!\n", len(configs))
+		return 0
+	}
+	fmt.Println("✅ Model generated successfully!")
+	return 0
+}
+
+func printConflicts(conflicts []model.Conflict) {
+	fmt.Fprintf(os.Stderr, "⚠️  %d existing file(s) would be overwritten:\n", len(conflicts))
+	fmt.Fprintln(os.Stderr, model.ConflictReport(conflicts))
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+	return 1
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mohsen-farahani/gokitgen/pkg/generator/model"
+)
+
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	out := fs.String("out", "./", "output directory the files were generated into")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gokitgen status [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	statuses, err := model.Status(model.DiskOutput{}, *out)
+	if err != nil {
+		return fail(err)
+	}
+	if len(statuses) == 0 {
+		fmt.Printf("No generated files recorded in %s.\n", *out)
+		return 0
+	}
+
+	counts := make(map[model.FileState]int)
+	for _, s := range statuses {
+		counts[s.State]++
+		if s.State == model.FileUnchanged {
+			continue
+		}
+		owner := s.Model
+		if owner == "" {
+			owner = "shared"
+		}
+		fmt.Printf("  %-9s %-56s %s\n", s.State, s.Path, owner)
+	}
+	fmt.Printf("📋 %d generated files: %d unchanged, %d modified, %d missing.\n",
+		len(statuses), counts[model.FileUnchanged], counts[model.FileModified], counts[model.FileMissing])
+	return 0
+}
+
+func runClean(args []string) int {
+	fs := flag.NewFlagSet("clean", flag.ContinueOnError)
+	var (
+		out    = fs.String("out", "./", "output directory the files were generated into")
+		dryRun = fs.Bool("dry-run", false, "list the files that would be removed without removing them")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gokitgen clean [flags] <Model>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	name := fs.Arg(0)
+
+	var output model.Output = model.DiskOutput{}
+	if *dryRun {
+		output = &model.DryRunOutput{W: os.Stdout}
+	}
+	removed, updated, kept, err := model.Clean(output, *out, name)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, s := range kept {
+		fmt.Printf("✋ Kept modified %s\n", s.Path)
+	}
+	for _, path := range updated {
+		fmt.Printf("✂️  Removed %s from %s\n", name, path)
+	}
+	if *dryRun {
+		fmt.Printf("🧪 %d files of %s would be removed. Nothing was changed.\n", len(removed), name)
+		return 0
+	}
+	fmt.Printf("🧹 Removed %d generated files of %s.\n", len(removed), name)
+	fmt.Printf("💡 The migrations of %s are kept; add one dropping its table if it should go.\n", name)
+	return 0
+}
@@ -0,0 +1,341 @@
+package model
+
+import (
+	"fmt"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+type Field struct {
+	Name           string   `yaml:"name" json:"name"`
+	Type           string   `yaml:"type" json:"type"`
+	TypeIsEnum     bool     `yaml:"enum,omitempty" json:"enum,omitempty"`
+	TypeIsRelation bool     `yaml:"relation,omitempty" json:"relation,omitempty"`
+	IsNullable     bool     `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Validation     []string `yaml:"validation,omitempty" json:"validation,omitempty"`
+	GormTag        string   `yaml:"gorm,omitempty" json:"gorm,omitempty"` // e.g., "default:0", "index", "unique"
+	Comment        string   `yaml:"comment,omitempty" json:"comment,omitempty"`
+	// ProtoNumber is the number of the field in the protobuf message; zero
+	// numbers the field after the others, see ModelConfig.ProtoNumbers.
+	ProtoNumber int `yaml:"proto_number,omitempty" json:"proto_number,omitempty"`
+}
+
+type Enum struct {
+	Name   string   `yaml:"name" json:"name"`
+	Values []string `yaml:"values" json:"values"`
+}
+
+// Operation is a CRUD operation generated for a model.
+type Operation string
+
+const (
+	OpCreate Operation = "create"
+	OpGet    Operation = "get"
+	OpList   Operation = "list"
+	OpUpdate Operation = "update"
+	OpPatch  Operation = "patch"
+	OpDelete Operation = "delete"
+)
+
+// AllOperations lists every operation in the order they are generated.
+var AllOperations = []Operation{OpCreate, OpGet, OpList, OpUpdate, OpPatch, OpDelete}
+
+type ModelConfig struct {
+	ModelName     string  `yaml:"name" json:"name"`
+	ModulePath    string  `yaml:"module,omitempty" json:"module,omitempty"`
+	Fields        []Field `yaml:"fields,omitempty" json:"fields,omitempty"`
+	Enums         []Enum  `yaml:"enums,omitempty" json:"enums,omitempty"`
+	GenerateHTTP  bool    `yaml:"http,omitempty" json:"http,omitempty"`
+	GenerategRPC  bool    `yaml:"grpc,omitempty" json:"grpc,omitempty"`
+	GenerateTests bool    `yaml:"tests,omitempty" json:"tests,omitempty"`
+	OutputPath    string  `yaml:"output,omitempty" json:"output,omitempty"`
+	// Operations selects the CRUD operations to generate; empty means all.
+	Operations []Operation `yaml:"operations,omitempty" json:"operations,omitempty"`
+	// DBManager is the type the repository is built from instead of a plain
+	// *gorm.DB, see ParseDBManager.
+	DBManager string `yaml:"db_manager,omitempty" json:"db_manager,omitempty"`
+	// Database is the dialect the model targets: mysql (the default),
+	// postgres or sqlite.
+	Database string `yaml:"database,omitempty" json:"database,omitempty"`
+}
+
+// DBManager describes a project type that hands out the *gorm.DB.
+type DBManager struct {
+	ImportPath string // e.g. github.com/acme/platform/db
+	Package    string // name the package is imported as, e.g. db
+	Type       string // e.g. Manager
+	Method     string // method returning the *gorm.DB, DB unless given
+}
+
+// ParseDBManager parses "import/path.Type" or "import/path.Type.Method",
+// e.g. "github.com/acme/platform/db.Manager.Gorm".
+func ParseDBManager(s string) (*DBManager, error) {
+	dir, last := "", s
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		dir, last = s[:i+1], s[i+1:]
+	}
+	parts := strings.Split(last, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid db manager %q: expected import/path.Type or import/path.Type.Method", s)
+	}
+
+	m := &DBManager{ImportPath: dir + parts[0], Type: parts[1], Method: "DB"}
+	if len(parts) == 3 {
+		m.Method = parts[2]
+	}
+	m.Package = importName(m.ImportPath)
+	if !token.IsIdentifier(m.Type) || !token.IsExported(m.Type) || !token.IsIdentifier(m.Method) || !token.IsExported(m.Method) {
+		return nil, fmt.Errorf("invalid db manager %q: type and method must be exported Go identifiers", s)
+	}
+	return m, nil
+}
+
+// Manager returns the parsed DBManager, or nil when the repository takes a
+// *gorm.DB.
+func (c *ModelConfig) Manager() *DBManager {
+	if c.DBManager == "" {
+		return nil
+	}
+	m, err := ParseDBManager(c.DBManager)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// Supports reports whether op is generated for the model.
+func (c *ModelConfig) Supports(op Operation) bool {
+	return len(c.Operations) == 0 || slices.Contains(c.Operations, op)
+}
+
+// UsesType reports whether a plain field of the model has type goType.
+func (c *ModelConfig) UsesType(goType string) bool {
+	return slices.ContainsFunc(c.Fields, func(f Field) bool {
+		return !f.TypeIsEnum && !f.TypeIsRelation && f.Type == goType
+	})
+}
+
+// protoReserved are the numbers the protobuf message gives id, created_at
+// and updated_at.
+const protoReserved = 3
+
+// ProtoNumbers returns the protobuf field number of each field: its
+// ProtoNumber when set, otherwise the numbers following the highest one in
+// use. Generating fills in ProtoNumber from the manifest first, see
+// assignProtoNumbers, so only new fields are numbered here.
+func (c *ModelConfig) ProtoNumbers() []int {
+	next := protoReserved
+	for _, f := range c.Fields {
+		next = max(next, f.ProtoNumber)
+	}
+	numbers := make([]int, len(c.Fields))
+	for i, f := range c.Fields {
+		if numbers[i] = f.ProtoNumber; numbers[i] == 0 {
+			next++
+			numbers[i] = next
+		}
+	}
+	return numbers
+}
+
+// SelectedOperations returns the operations generated for the model in
+// the order of AllOperations.
+func (c *ModelConfig) SelectedOperations() []Operation {
+	var ops []Operation
+	for _, op := range AllOperations {
+		if c.Supports(op) {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// Title returns the operation name as used in Go identifiers, e.g. "List".
+func (op Operation) Title() string {
+	if op == "" {
+		return ""
+	}
+	return strings.ToUpper(string(op[:1])) + string(op[1:])
+}
+
+// ParseOperations parses a comma separated list such as "create,get,list".
+// "all" selects every operation.
+func ParseOperations(s string) ([]Operation, error) {
+	var ops []Operation
+	for _, part := range strings.Split(s, ",") {
+		op := Operation(strings.ToLower(strings.TrimSpace(part)))
+		switch {
+		case op == "":
+		case op == "all":
+			return nil, nil
+		case !slices.Contains(AllOperations, op):
+			return nil, fmt.Errorf("unknown operation %q (expected %s)", part, joinOperations(AllOperations))
+		case !slices.Contains(ops, op):
+			ops = append(ops, op)
+		}
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("at least one operation is required")
+	}
+	return ops, nil
+}
+
+func joinOperations(ops []Operation) string {
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = string(op)
+	}
+	return strings.Join(names, ", ")
+}
+
+// NewField builds a field from a name and a wizard-style type such as
+// "uint", "OrderStatus" (a declared enum) or "Ref:Market" (a relation).
+func NewField(name, typ string, enums []Enum) Field {
+	field := Field{Name: name, Type: typ}
+
+	if strings.HasPrefix(typ, "Ref:") {
+		field.TypeIsRelation = true
+		field.Type = strings.TrimPrefix(typ, "Ref:")
+		return field
+	}
+
+	for _, e := range enums {
+		if e.Name == typ {
+			field.TypeIsEnum = true
+			break
+		}
+	}
+	return field
+}
+
+// ParseField parses the "Name:Type" form used on the command line. A
+// leading "*" on the type marks the field as nullable, e.g. "Note:*string".
+func ParseField(s string, enums []Enum) (Field, error) {
+	name, typ, ok := strings.Cut(s, ":")
+	name, typ = strings.TrimSpace(name), strings.TrimSpace(typ)
+	if !ok || name == "" || typ == "" {
+		return Field{}, fmt.Errorf("invalid field %q: expected Name:Type", s)
+	}
+
+	nullable := strings.HasPrefix(typ, "*")
+	field := NewField(name, strings.TrimPrefix(typ, "*"), enums)
+	field.IsNullable = nullable
+	return field, nil
+}
+
+// ParseEnum parses the "Name=VALUE1,VALUE2" form used on the command line.
+func ParseEnum(s string) (Enum, error) {
+	name, values, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return Enum{}, fmt.Errorf("invalid enum %q: expected Name=VALUE1,VALUE2", s)
+	}
+
+	enum := Enum{Name: name}
+	for _, v := range strings.Split(values, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			enum.Values = append(enum.Values, v)
+		}
+	}
+	if len(enum.Values) == 0 {
+		return Enum{}, fmt.Errorf("enum %s: at least one value is required", name)
+	}
+	return enum, nil
+}
+
+// ValidateName checks that name can be used as an exported Go identifier,
+// which is how models, fields and enums end up in the generated code.
+func ValidateName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name is required", kind)
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return fmt.Errorf("%s name %q must be an exported Go identifier (e.g. Order)", kind, name)
+	}
+	return nil
+}
+
+// Validate reports the first problem that would stop the generator from
+// producing usable code for this config.
+func (c *ModelConfig) Validate() error {
+	if err := ValidateName("model", c.ModelName); err != nil {
+		return err
+	}
+	if c.ModulePath == "" {
+		return fmt.Errorf("module path is required")
+	}
+	if c.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+
+	for _, op := range c.Operations {
+		if !slices.Contains(AllOperations, op) {
+			return fmt.Errorf("unknown operation %q (expected %s)", op, joinOperations(AllOperations))
+		}
+	}
+	if c.DBManager != "" {
+		if _, err := ParseDBManager(c.DBManager); err != nil {
+			return err
+		}
+	}
+	if c.Database != "" && !slices.Contains(Databases, c.Database) {
+		return fmt.Errorf("unknown database %q (expected %s)", c.Database, strings.Join(Databases, ", "))
+	}
+
+	enums := make(map[string]bool)
+	for _, e := range c.Enums {
+		if err := ValidateName("enum", e.Name); err != nil {
+			return err
+		}
+		if enums[e.Name] {
+			return fmt.Errorf("enum %s is declared more than once", e.Name)
+		}
+		if len(e.Values) == 0 {
+			return fmt.Errorf("enum %s: at least one value is required", e.Name)
+		}
+		enums[e.Name] = true
+	}
+
+	fields := make(map[string]bool)
+	for _, f := range c.Fields {
+		if err := ValidateName("field", f.Name); err != nil {
+			return err
+		}
+		if f.Type == "" {
+			return fmt.Errorf("field %s: type is required", f.Name)
+		}
+		if fields[f.Name] {
+			return fmt.Errorf("field %s is declared more than once", f.Name)
+		}
+		if f.TypeIsEnum && !enums[f.Type] {
+			return fmt.Errorf("field %s: unknown enum %s", f.Name, f.Type)
+		}
+		if !f.TypeIsEnum && !f.TypeIsRelation {
+			if err := ValidateType(f.Type); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		fields[f.Name] = true
+	}
+
+	numbers := make(map[int]string)
+	for _, f := range c.Fields {
+		n := f.ProtoNumber
+		switch {
+		case n == 0:
+			continue
+		case n < 0 || n > 536870911:
+			return fmt.Errorf("field %s: proto number %d is out of range", f.Name, n)
+		case n <= protoReserved:
+			return fmt.Errorf("field %s: proto numbers 1 to %d are used by id, created_at and updated_at", f.Name, protoReserved)
+		case n >= 19000 && n <= 19999:
+			return fmt.Errorf("field %s: proto numbers 19000 to 19999 are reserved by protobuf", f.Name)
+		}
+		if other, ok := numbers[n]; ok {
+			return fmt.Errorf("fields %s and %s have the same proto number %d", other, f.Name, n)
+		}
+		numbers[n] = f.Name
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Database dialects the generated code can target.
+const (
+	DatabaseMySQL    = "mysql"
+	DatabasePostgres = "postgres"
+	DatabaseSQLite   = "sqlite"
+)
+
+// Databases lists the supported dialects; the first one is the default.
+var Databases = []string{DatabaseMySQL, DatabasePostgres, DatabaseSQLite}
+
+// Dialect returns the database the model targets, MySQL unless set.
+func (c *ModelConfig) Dialect() string {
+	if c.Database == "" {
+		return DatabaseMySQL
+	}
+	return c.Database
+}
+
+// tableName is the table GORM's default naming strategy picks for a model:
+// the snake_case name in plural, e.g. OrderItem -> order_items.
+func tableName(model string) string {
+	name := snake(model)
+	switch {
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// columnName is the column of a field, e.g. Market -> market_id.
+func columnName(f Field) string {
+	if f.TypeIsRelation {
+		return snake(f.Name) + "_id"
+	}
+	return snake(f.Name)
+}
+
+// isJSONColumn reports whether values of goType are stored as JSON.
+func isJSONColumn(goType string) bool {
+	return goType != "[]byte" && isNilable(goType)
+}
+
+// columnType returns the SQL type of the column that stores f.
+func columnType(dialect string, f Field, enums []Enum) string {
+	if f.TypeIsRelation {
+		return pick(dialect, "bigint unsigned", "bigint", "integer")
+	}
+	if f.TypeIsEnum {
+		values := enumValues(f.Type, enums)
+		if dialect == DatabaseMySQL {
+			quoted := make([]string, len(values))
+			for i, v := range values {
+				quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+			}
+			return "enum(" + strings.Join(quoted, ",") + ")"
+		}
+		size := 1
+		for _, v := range values {
+			size = max(size, len(v))
+		}
+		return pick(dialect, "", fmt.Sprintf("varchar(%d)", size), "text")
+	}
+
+	switch f.Type {
+	case "string":
+		return pick(dialect, "varchar(255)", "text", "text")
+	case "bool":
+		return pick(dialect, "boolean", "boolean", "numeric")
+	case "int", "int64":
+		return pick(dialect, "bigint", "bigint", "integer")
+	case "uint", "uint64":
+		return pick(dialect, "bigint unsigned", "bigint", "integer")
+	case "int8", "int16", "uint8", "uint16":
+		return pick(dialect, "smallint", "smallint", "integer")
+	case "int32", "uint32":
+		return pick(dialect, "int", "integer", "integer")
+	case "float32":
+		return pick(dialect, "float", "real", "real")
+	case "float64":
+		return pick(dialect, "double", "double precision", "real")
+	case "time.Time":
+		return pick(dialect, "datetime(3)", "timestamptz", "datetime")
+	case "time.Duration":
+		return pick(dialect, "bigint", "bigint", "integer")
+	case "uuid.UUID":
+		return pick(dialect, "char(36)", "uuid", "text")
+	case "decimal.Decimal":
+		return pick(dialect, "decimal(19,4)", "numeric(19,4)", "numeric")
+	case "[]byte":
+		return pick(dialect, "longblob", "bytea", "blob")
+	}
+	if isJSONColumn(f.Type) {
+		return pick(dialect, "json", "jsonb", "text")
+	}
+	return pick(dialect, "text", "text", "text")
+}
+
+func pick(dialect, mysql, postgres, sqlite string) string {
+	switch dialect {
+	case DatabasePostgres:
+		return postgres
+	case DatabaseSQLite:
+		return sqlite
+	}
+	return mysql
+}
+
+func enumValues(name string, enums []Enum) []string {
+	for _, e := range enums {
+		if e.Name == name {
+			return e.Values
+		}
+	}
+	return nil
+}
+
+// gormTag returns the gorm struct tag of a non-relation field: the column
+// type where GORM's choice does not fit the dialect (enums, JSON, UUIDs,
+// decimals), followed by the tag given in the spec, whose type and default
+// win.
+func gormTag(c *ModelConfig, f Field) string {
+	dialect := c.Dialect()
+	given := gormSettings(f.GormTag)
+	_, typed := given["type"]
+	var parts []string
+	switch {
+	case (f.TypeIsEnum || f.Type == "uuid.UUID" || f.Type == "decimal.Decimal") && !typed:
+		parts = append(parts, "type:"+columnType(dialect, f, c.Enums))
+	case isJSONColumn(f.Type) && !typed:
+		parts = append(parts, "type:"+columnType(dialect, f, c.Enums), "serializer:json")
+	case isJSONColumn(f.Type):
+		parts = append(parts, "serializer:json")
+	}
+	if _, ok := given["default"]; !ok && f.Type == "uuid.UUID" && dialect == DatabasePostgres && !f.IsNullable {
+		parts = append(parts, "default:gen_random_uuid()")
+	}
+	if f.GormTag != "" {
+		parts = append(parts, f.GormTag)
+	}
+	return strings.Join(parts, ";")
+}
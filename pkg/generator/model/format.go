@@ -0,0 +1,339 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// knownImports maps the package names used by the templates to their import
+// paths, so a template that forgets an import still produces valid code.
+// Paths starting with "/" are relative to the module of the generated
+// project.
+var knownImports = map[string]string{
+	"bytes":        "bytes",
+	"context":      "context",
+	"errors":       "errors",
+	"fmt":          "fmt",
+	"http":         "net/http",
+	"httptest":     "net/http/httptest",
+	"json":         "encoding/json",
+	"strconv":      "strconv",
+	"strings":      "strings",
+	"testing":      "testing",
+	"time":         "time",
+	"assert":       "github.com/stretchr/testify/assert",
+	"require":      "github.com/stretchr/testify/require",
+	"codes":        "google.golang.org/grpc/codes",
+	"grpc":         "google.golang.org/grpc",
+	"status":       "google.golang.org/grpc/status",
+	"endpoint":     "github.com/go-kit/kit/endpoint",
+	"kithttp":      "github.com/go-kit/kit/transport/http",
+	"mux":          "github.com/gorilla/mux",
+	"gorm":         "gorm.io/gorm",
+	"timestamppb":  "google.golang.org/protobuf/types/known/timestamppb",
+	"fieldmaskpb":  "google.golang.org/protobuf/types/known/fieldmaskpb",
+	"durationpb":   "google.golang.org/protobuf/types/known/durationpb",
+	"uuid":         "github.com/google/uuid",
+	"decimal":      "github.com/shopspring/decimal",
+	"dto":          "/internal/service/dto",
+	"endpoints":    "/internal/api/endpoints",
+	"mappers":      "/internal/mappers",
+	"models":       "/internal/models",
+	"repositories": "/internal/repositories",
+	"service":      "/internal/service",
+}
+
+// importName guesses the name a package is referred to by when imported
+// without an alias: the last path element, skipping a major version suffix.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if isVersion(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isVersion(name[i+1:]) {
+		name = name[:i] // gopkg.in/yaml.v3
+	}
+	return strings.NewReplacer("-", "", ".", "").Replace(name)
+}
+
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// formatGo fixes the imports of a rendered Go file and runs it through
+// go/format. Unused imports are dropped and missing ones found in
+// knownImports are added.
+func formatGo(src []byte, modulePath string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	type cut struct{ from, to int }
+	var (
+		cuts     []cut
+		imported = make(map[string]bool)
+	)
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	for _, spec := range file.Imports {
+		name := specName(spec)
+		imported[name] = true
+		if name == "_" || name == "." || used[name] {
+			continue
+		}
+		from := bytes.LastIndexByte(src[:offset(spec.Pos())], '\n') + 1
+		to := offset(spec.End())
+		if i := bytes.IndexByte(src[to:], '\n'); i >= 0 {
+			to += i + 1
+		}
+		cuts = append(cuts, cut{from, to})
+	}
+
+	var missing []string
+	for name := range used {
+		importPath, ok := knownImports[name]
+		if !ok || imported[name] {
+			continue
+		}
+		if strings.HasPrefix(importPath, "/") {
+			importPath = modulePath + importPath
+		}
+		if importName(importPath) == name {
+			missing = append(missing, strconv.Quote(importPath))
+		} else {
+			missing = append(missing, name+" "+strconv.Quote(importPath))
+		}
+	}
+	if len(cuts) == 0 && len(missing) == 0 {
+		return format.Source(src)
+	}
+	sort.Strings(missing)
+
+	// Missing imports join the group of their kind in the first import
+	// declaration, like goimports adds them.
+	group := func(spec string) int {
+		p, _ := strconv.Unquote(spec[strings.IndexByte(spec, '"'):])
+		switch {
+		case modulePath != "" && (p == modulePath || strings.HasPrefix(p, modulePath+"/")):
+			return 2
+		case strings.Contains(strings.Split(p, "/")[0], "."):
+			return 1
+		}
+		return 0
+	}
+	lineEnd := func(pos token.Pos) int {
+		off := offset(pos)
+		if i := bytes.IndexByte(src[off:], '\n'); i >= 0 {
+			return off + i + 1
+		}
+		return len(src)
+	}
+	lineStart := func(pos token.Pos) int {
+		return bytes.LastIndexByte(src[:offset(pos)], '\n') + 1
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range file.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+	// Edits with an empty range insert their text.
+	type edit struct {
+		from, to int
+		text     string
+	}
+	var edits []edit
+	for _, c := range cuts {
+		edits = append(edits, edit{from: c.from, to: c.to})
+	}
+	insert := func(offset int, text string) {
+		edits = append(edits, edit{from: offset, to: offset, text: text})
+	}
+	switch {
+	case len(missing) == 0:
+	case decl == nil || !decl.Lparen.IsValid():
+		// A declaration of its own, or a single import turned into a block.
+		at := offset(file.Name.End())
+		specs := missing
+		if decl != nil {
+			at = lineStart(decl.Pos())
+			edits = append(edits, edit{from: at, to: lineEnd(decl.End())})
+			if spec := decl.Specs[0].(*ast.ImportSpec); used[specName(spec)] || spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".") {
+				specs = append(specs, string(src[offset(spec.Pos()):offset(spec.End())]))
+			}
+		}
+		var groups [3][]string
+		for _, spec := range specs {
+			groups[group(spec)] = append(groups[group(spec)], "\t"+spec+"\n")
+		}
+		var block []string
+		for _, g := range groups {
+			if len(g) > 0 {
+				sort.Strings(g)
+				block = append(block, strings.Join(g, ""))
+			}
+		}
+		text := "import (\n" + strings.Join(block, "\n") + ")\n"
+		if decl == nil {
+			text = "\n\n" + strings.TrimSuffix(text, "\n")
+		}
+		insert(at, text)
+	default:
+		var specs [3][]*ast.ImportSpec
+		for _, spec := range decl.Specs {
+			s := spec.(*ast.ImportSpec)
+			specs[group(s.Path.Value)] = append(specs[group(s.Path.Value)], s)
+		}
+		var orphans [3]string
+		for _, spec := range missing {
+			g := group(spec)
+			if n := len(specs[g]); n > 0 {
+				insert(lineEnd(specs[g][n-1].End()), "\t"+spec+"\n")
+			} else {
+				orphans[g] += "\t" + spec + "\n"
+			}
+		}
+		// Kinds the block has no group for yet get one of their own.
+		if orphans[0] != "" {
+			insert(lineEnd(decl.Lparen), orphans[0]+"\n")
+		}
+		if orphans[1] != "" {
+			if len(specs[2]) > 0 {
+				insert(lineStart(specs[2][0].Pos()), orphans[1]+"\n")
+			} else {
+				insert(lineStart(decl.Rparen), "\n"+orphans[1])
+			}
+		}
+		if orphans[2] != "" {
+			insert(lineStart(decl.Rparen), "\n"+orphans[2])
+		}
+	}
+
+	// Inserts go before the cuts starting at the same offset.
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].from != edits[j].from {
+			return edits[i].from < edits[j].from
+		}
+		return edits[i].to == edits[i].from && edits[j].to != edits[j].from
+	})
+	var out bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.from > last {
+			out.Write(src[last:e.from])
+			last = e.from
+		}
+		out.WriteString(e.text)
+		last = max(last, e.to)
+	}
+	out.Write(src[last:])
+	return format.Source(out.Bytes())
+}
+
+// specName returns the name an import is referred to by.
+func specName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	return importName(importPath)
+}
+
+// TemplateError reports Go code a template produced that does not parse or
+// type-check. Line is the line in the generated file; TemplateLine is the
+// template line that most likely produced it, or 0 when unknown. Template
+// is empty for hand-written files checked along with the generated ones.
+type TemplateError struct {
+	Path         string
+	Line         int
+	Template     string
+	TemplateLine int
+	Msg          string
+}
+
+func (e *TemplateError) Error() string {
+	if e.Template == "" {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+	}
+	tmpl := e.Template
+	if e.TemplateLine > 0 {
+		tmpl = fmt.Sprintf("%s:%d", e.Template, e.TemplateLine)
+	}
+	return fmt.Sprintf("template %s: %s:%d: %s", tmpl, e.Path, e.Line, e.Msg)
+}
+
+// newTemplateError builds a TemplateError for line of the file generated
+// from the template called name.
+func newTemplateError(name, filePath string, content []byte, line int, msg string) *TemplateError {
+	e := &TemplateError{Path: filePath, Line: line, Template: name, Msg: msg}
+	lines := strings.Split(string(content), "\n")
+	if name != "" && line > 0 && line <= len(lines) {
+		if src, err := tmplFS.ReadFile("templates/" + name); err == nil {
+			e.TemplateLine = templateLine(string(src), lines[line-1])
+		}
+	}
+	return e
+}
+
+// syntaxError converts a go/scanner error list into a TemplateError.
+func syntaxError(name, filePath string, content []byte, err error) error {
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return newTemplateError(name, filePath, content, list[0].Pos.Line, list[0].Msg)
+	}
+	return fmt.Errorf("template %s: %s: %w", name, filePath, err)
+}
+
+var templateAction = regexp.MustCompile(`{{.*?}}`)
+
+// templateLine finds the template line that renders generated. Actions are
+// treated as wildcards and whitespace is ignored, since gofmt may have
+// changed it; of the matching lines the one with the most literal text wins.
+func templateLine(tmpl, generated string) int {
+	want := strings.Join(strings.Fields(generated), "")
+	if want == "" {
+		return 0
+	}
+	best, bestLiteral := 0, 0
+	for i, line := range strings.Split(tmpl, "\n") {
+		parts := templateAction.Split(strings.Join(strings.Fields(line), ""), -1)
+		literal := len(strings.Join(parts, ""))
+		if literal <= bestLiteral {
+			continue
+		}
+		for j, p := range parts {
+			parts[j] = regexp.QuoteMeta(p)
+		}
+		re, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+		if err == nil && re.MatchString(want) {
+			best, bestLiteral = i+1, literal
+		}
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package model
+
+import "testing"
+
+func TestFormatGo(t *testing.T) {
+	const module = "example.com/shop"
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "unused imports dropped",
+			src:  "package a\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\nvar _ = fmt.Sprint\n",
+			want: "package a\n\nimport (\n\t\"fmt\"\n)\n\nvar _ = fmt.Sprint\n",
+		},
+		{
+			name: "missing imports join their group",
+			src: "package a\n\nimport (\n\t\"fmt\"\n\n\t\"gorm.io/gorm\"\n\n\t\"example.com/shop/internal/models\"\n)\n\n" +
+				"var _ = fmt.Sprint\nvar _ = json.Marshal\nvar _ *gorm.DB\nvar _ = uuid.New\nvar _ models.Order\nvar _ dto.Order\n",
+			want: "package a\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n\n\t\"github.com/google/uuid\"\n\t\"gorm.io/gorm\"\n\n\t\"example.com/shop/internal/models\"\n\t\"example.com/shop/internal/service/dto\"\n)\n\n" +
+				"var _ = fmt.Sprint\nvar _ = json.Marshal\nvar _ *gorm.DB\nvar _ = uuid.New\nvar _ models.Order\nvar _ dto.Order\n",
+		},
+		{
+			name: "missing group added",
+			src:  "package a\n\nimport (\n\t\"example.com/shop/internal/models\"\n)\n\nvar _ models.Order\nvar _ = fmt.Sprint\nvar _ *gorm.DB\n",
+			want: "package a\n\nimport (\n\t\"fmt\"\n\n\t\"gorm.io/gorm\"\n\n\t\"example.com/shop/internal/models\"\n)\n\nvar _ models.Order\nvar _ = fmt.Sprint\nvar _ *gorm.DB\n",
+		},
+		{
+			name: "single import turned into a block",
+			src:  "package a\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint\nvar _ = strings.TrimSpace\n",
+			want: "package a\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\nvar _ = fmt.Sprint\nvar _ = strings.TrimSpace\n",
+		},
+		{
+			name: "no import declaration",
+			src:  "package a\n\nvar _ = fmt.Sprint\n",
+			want: "package a\n\nimport (\n\t\"fmt\"\n)\n\nvar _ = fmt.Sprint\n",
+		},
+		{
+			name: "blank and local names kept",
+			src:  "package a\n\nimport (\n\t_ \"embed\"\n\n\tpb \"example.com/shop/api/proto/v1\"\n)\n\nvar _ pb.Order\n",
+			want: "package a\n\nimport (\n\t_ \"embed\"\n\n\tpb \"example.com/shop/api/proto/v1\"\n)\n\nvar _ pb.Order\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGo([]byte(tt.src), module)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatGo =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"encoding/json":                "json",
+		"github.com/go-kit/kit/v2":     "kit",
+		"gopkg.in/yaml.v3":             "yaml",
+		"github.com/go-sql-driver/sql": "sql",
+		"github.com/mattn/go-sqlite3":  "gosqlite3",
+	}
+	for path, want := range tests {
+		if got := importName(path); got != want {
+			t.Errorf("importName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
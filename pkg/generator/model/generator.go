@@ -0,0 +1,272 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// OverwritePolicy decides what happens to generated files that already
+// exist with different content.
+type OverwritePolicy int
+
+const (
+	// OverwriteAsk calls Generator.Confirm, or fails with a ConflictError
+	// when there is nobody to ask.
+	OverwriteAsk OverwritePolicy = iota
+	// OverwriteForce replaces existing files.
+	OverwriteForce
+	// OverwriteSkip keeps existing files and only writes new ones.
+	OverwriteSkip
+)
+
+// Conflict is a generated file that already exists with other content.
+type Conflict struct {
+	Path     string
+	Template string
+	Added    int // lines only in the new content
+	Removed  int // lines only in the existing content
+}
+
+// ConflictError is returned when existing files would be overwritten and
+// the overwrite was not allowed.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	paths := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		paths[i] = c.Path
+	}
+	return fmt.Sprintf("%d existing file(s) would be overwritten: %s", len(e.Conflicts), strings.Join(paths, ", "))
+}
+
+// Generator renders templates and writes the result through an Output.
+type Generator struct {
+	Output    Output
+	Overwrite OverwritePolicy
+	// Confirm is asked what to do about conflicts when Overwrite is
+	// OverwriteAsk. It returns OverwriteForce or OverwriteSkip; an error
+	// (such as ErrAborted) stops the run before anything is written.
+	Confirm func(conflicts []Conflict) (OverwritePolicy, error)
+}
+
+func NewGenerator(out Output) *Generator {
+	return &Generator{Output: out}
+}
+
+// GenerateCode generates every layer for each model and a single combined
+// routes/gRPC registration covering all of them, writing straight to disk.
+// Existing files with other content are never overwritten; use a Generator
+// to choose a different OverwritePolicy. All models must share the same
+// module and output path.
+func GenerateCode(configs ...*ModelConfig) error {
+	if err := NewGenerator(DiskOutput{}).Generate(configs...); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Code generated successfully in %s\n", configs[0].OutputPath)
+	return nil
+}
+
+// Plan renders the files for configs, carries the user regions of existing
+// files over, extends existing shared files with new models and reports which files would overwrite existing ones in
+// g.Output. Files still matching the content recorded in the manifest were
+// not edited by hand and are not reported. Nothing is written.
+func (g *Generator) Plan(configs ...*ModelConfig) ([]GeneratedFile, []Conflict, error) {
+	files, conflicts, _, err := g.plan(configs...)
+	return files, conflicts, err
+}
+
+func (g *Generator) plan(configs ...*ModelConfig) ([]GeneratedFile, []Conflict, *Manifest, error) {
+	outputPath := configs[0].OutputPath
+	manifest, err := LoadManifest(g.Output, outputPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	configs = assignProtoNumbers(configs, manifest)
+	files, err := RenderFiles(configs...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if files, err = planMigrations(files, configs, manifest); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var conflicts []Conflict
+	for i := range files {
+		file := &files[i]
+		existing, err := g.Output.ReadFile(file.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if file.Shared {
+			if merged, err := mergeShared(existing, file.Content); err != nil {
+				file.Content, file.mergeErr = existing, err
+			} else {
+				file.Content = merged
+			}
+			continue
+		}
+		if file.Content, err = mergeRegions(file.Content, existing); err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		if bytes.Equal(existing, file.Content) {
+			continue
+		}
+		// Files edited only inside their user regions are not edited as far
+		// as regenerating is concerned: the regions were carried over above.
+		if entry, ok := manifest.Lookup(outputPath, file.Path); ok &&
+			(entry.ContentHash == hashContent(existing) || entry.OutsideHash == hashContent(stripRegions(existing))) {
+			continue
+		}
+
+		added, removed := lineChanges(existing, file.Content)
+		conflicts = append(conflicts, Conflict{Path: file.Path, Template: file.Template, Added: added, Removed: removed})
+	}
+	return files, conflicts, manifest, nil
+}
+
+// assignProtoNumbers returns configs with the fields that have no
+// ProtoNumber given the number manifest recorded for them, so adding or
+// moving a field does not renumber the others. Fields new to the manifest
+// are numbered after every number it recorded for the model, including
+// those of removed fields, and the numbers are recorded in manifest. The
+// configs passed in are left as they are.
+func assignProtoNumbers(configs []*ModelConfig, manifest *Manifest) []*ModelConfig {
+	if manifest.ProtoNumbers == nil {
+		manifest.ProtoNumbers = make(map[string]map[string]int)
+	}
+	assigned := make([]*ModelConfig, len(configs))
+	for i, config := range configs {
+		assigned[i] = config
+		if !config.GenerategRPC {
+			continue
+		}
+		c := *config
+		c.Fields = slices.Clone(config.Fields)
+		assigned[i] = &c
+
+		recorded := manifest.ProtoNumbers[c.ModelName]
+		if recorded == nil {
+			recorded = make(map[string]int)
+			manifest.ProtoNumbers[c.ModelName] = recorded
+		}
+		next := protoReserved
+		taken := make(map[int]bool)
+		for _, n := range recorded {
+			next = max(next, n)
+		}
+		for _, f := range c.Fields {
+			if f.ProtoNumber != 0 {
+				next = max(next, f.ProtoNumber)
+				taken[f.ProtoNumber] = true
+			}
+		}
+		for j := range c.Fields {
+			f := &c.Fields[j]
+			if n, ok := recorded[f.Name]; ok && f.ProtoNumber == 0 && !taken[n] {
+				f.ProtoNumber = n
+				taken[n] = true
+			}
+		}
+		for j := range c.Fields {
+			f := &c.Fields[j]
+			if f.ProtoNumber == 0 {
+				next++
+				f.ProtoNumber = next
+			}
+			recorded[f.Name] = f.ProtoNumber
+		}
+	}
+	return assigned
+}
+
+// Generate renders the files for configs and writes them to g.Output,
+// applying g.Overwrite to files that already exist. Every file that ends up
+// matching the generated content is recorded in the manifest.
+func (g *Generator) Generate(configs ...*ModelConfig) error {
+	files, conflicts, manifest, err := g.plan(configs...)
+	if err != nil {
+		return err
+	}
+
+	policy := g.Overwrite
+	if len(conflicts) > 0 && policy == OverwriteAsk {
+		if g.Confirm == nil {
+			return &ConflictError{Conflicts: conflicts}
+		}
+		if policy, err = g.Confirm(conflicts); err != nil {
+			return err
+		}
+		if policy == OverwriteAsk {
+			return &ConflictError{Conflicts: conflicts}
+		}
+	}
+
+	skip := make(map[string]bool)
+	if policy == OverwriteSkip {
+		for _, c := range conflicts {
+			skip[c.Path] = true
+			fmt.Printf("⏭️  Skipped existing %s\n", c.Path)
+		}
+	}
+
+	outputPath := configs[0].OutputPath
+	for _, file := range files {
+		if skip[file.Path] {
+			continue
+		}
+
+		for _, warning := range file.warnings {
+			fmt.Printf("⚠️  %s: %s\n", filepath.Base(file.Path), warning)
+		}
+
+		if file.mergeErr != nil {
+			fmt.Printf("⚠️  %s could not be updated automatically (%v) — please register the new models by hand.\n", filepath.Base(file.Path), file.mergeErr)
+			continue
+		}
+
+		existing, err := g.Output.ReadFile(file.Path)
+		if err == nil {
+			if bytes.Equal(existing, file.Content) {
+				manifest.record(outputPath, file)
+				continue
+			}
+		}
+
+		if err := g.Output.WriteFile(file.Path, file.Content); err != nil {
+			return err
+		}
+		manifest.record(outputPath, file)
+	}
+	return manifest.save(g.Output, outputPath)
+}
+
+// lineChanges counts the lines that only appear in one of the two versions.
+// It ignores order, which is plenty for a conflict summary.
+func lineChanges(old, new []byte) (added, removed int) {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(string(old), "\n") {
+		counts[line]++
+	}
+	for _, line := range strings.Split(string(new), "\n") {
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		added++
+	}
+	for _, n := range counts {
+		removed += n
+	}
+	return added, removed
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssignProtoNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []Field
+		recorded map[string]int
+		want     []int
+	}{
+		{
+			name:   "first run",
+			fields: []Field{{Name: "A"}, {Name: "B"}},
+			want:   []int{4, 5},
+		},
+		{
+			name:     "field inserted before the others",
+			fields:   []Field{{Name: "New"}, {Name: "A"}, {Name: "B"}},
+			recorded: map[string]int{"A": 4, "B": 5},
+			want:     []int{6, 4, 5},
+		},
+		{
+			name:     "number of a removed field not reused",
+			fields:   []Field{{Name: "A"}, {Name: "New"}},
+			recorded: map[string]int{"A": 4, "Gone": 5},
+			want:     []int{4, 6},
+		},
+		{
+			name:     "explicit numbers win",
+			fields:   []Field{{Name: "A", ProtoNumber: 5}, {Name: "B"}},
+			recorded: map[string]int{"A": 4, "B": 5},
+			want:     []int{5, 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &ModelConfig{ModelName: "Order", GenerategRPC: true, Fields: tt.fields}
+			manifest := &Manifest{}
+			if tt.recorded != nil {
+				manifest.ProtoNumbers = map[string]map[string]int{"Order": tt.recorded}
+			}
+			assigned := assignProtoNumbers([]*ModelConfig{config}, manifest)
+			if got := assigned[0].ProtoNumbers(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("numbers = %v, want %v", got, tt.want)
+			}
+			for i, f := range assigned[0].Fields {
+				if manifest.ProtoNumbers["Order"][f.Name] != tt.want[i] {
+					t.Errorf("recorded %s = %d, want %d", f.Name, manifest.ProtoNumbers["Order"][f.Name], tt.want[i])
+				}
+			}
+			if config.Fields[len(config.Fields)-1].ProtoNumber != tt.fields[len(tt.fields)-1].ProtoNumber {
+				t.Error("the config passed in was changed")
+			}
+		})
+	}
+}
+
+func TestGenerateKeepsProtoNumbers(t *testing.T) {
+	out := NewMemoryOutput()
+	config := &ModelConfig{
+		ModelName: "Order", ModulePath: "example.com/shop", OutputPath: "svc", GenerategRPC: true,
+		Fields: []Field{{Name: "Title", Type: "string"}},
+	}
+	if err := NewGenerator(out).Generate(config); err != nil {
+		t.Fatal(err)
+	}
+	config.Fields = append([]Field{{Name: "Code", Type: "string"}}, config.Fields...)
+	if err := NewGenerator(out).Generate(config); err != nil {
+		t.Fatal(err)
+	}
+
+	proto := string(out.Files[filepath.Join("svc", "api", "proto", "v1", "order.proto")])
+	for _, want := range []string{"string code = 5;", "string title = 4;"} {
+		if !strings.Contains(proto, want) {
+			t.Errorf("order.proto does not contain %q:\n%s", want, proto)
+		}
+	}
+}
+
+func TestRenderPatchChecksValues(t *testing.T) {
+	files, err := RenderFiles(&ModelConfig{
+		ModelName: "Order", ModulePath: "example.com/shop", OutputPath: "svc",
+		Enums:  []Enum{{Name: "OrderStatus", Values: []string{"PENDING", "PAID"}}},
+		Fields: []Field{{Name: "Status", Type: "OrderStatus", TypeIsEnum: true}, {Name: "Tags", Type: "[]string", IsNullable: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var service string
+	for _, f := range files {
+		if strings.HasSuffix(f.Path, "order_service.go") {
+			service = string(f.Content)
+		}
+	}
+	for _, want := range []string{
+		`"tags": true,`,        // only nullable fields take null
+		"!m.Status.Valid()",    // enums must hold one of their values
+		"json.Marshal(m.Tags)", // JSON columns are encoded for the map update
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("order_service.go does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package model
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// gormModelColumns are the fields gorm.Model declares; the generated model
+// embeds gorm.Model, so they are not imported as fields.
+var gormModelColumns = []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt"}
+
+// gormModelMismatch describes how the type of an imported property differs
+// from the gorm.Model field that takes its place, e.g. a string id. It
+// returns "" when they agree or goType is not known.
+func gormModelMismatch(fieldName, goType string) string {
+	if fieldName == "Id" || fieldName == "ID" {
+		integers := []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64"}
+		if goType == "" || slices.Contains(integers, goType) {
+			return ""
+		}
+		return goType + ", while gorm.Model's ID is uint"
+	}
+	if goType == "" || goType == "time.Time" {
+		return ""
+	}
+	return goType + ", while gorm.Model's " + fieldName + " is time.Time"
+}
+
+// ImportGoStruct builds a model from the struct typeName declared in the Go
+// file at path:
+//
+//   - pointers become nullable fields
+//   - the gorm tag becomes GormTag, the validate tag Validation and the
+//     field's comment Comment
+//   - string types with constants, declared anywhere in the package,
+//     become enums
+//   - a struct field next to its XID foreign key becomes a relation
+//
+// gorm.Model and its columns are skipped, as are fields gorm ignores
+// (gorm:"-") and fields the generator has no equivalent for, such as
+// has-many slices. They are listed in skipped.
+// Module and output path are left for the caller to fill in.
+func ImportGoStruct(path, typeName string) (config *ModelConfig, skipped []string, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := structType(file, typeName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Enums and the structs of relations are often declared in other files
+	// of the package.
+	pkg := []*ast.File{file}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.go"))
+	self := absPath(path)
+	for _, name := range matches {
+		if strings.HasSuffix(name, "_test.go") || absPath(name) == self {
+			continue
+		}
+		if f, err := parser.ParseFile(fset, name, nil, 0); err == nil && f.Name.Name == file.Name.Name {
+			pkg = append(pkg, f)
+		}
+	}
+	enums, structs := packageTypes(pkg)
+
+	config = &ModelConfig{ModelName: typeName}
+	fieldNames := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			fieldNames[name.Name] = true
+		}
+	}
+
+	usedEnums := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		typ := types.ExprString(f.Type)
+		if len(f.Names) == 0 {
+			if typ != "gorm.Model" {
+				skipped = append(skipped, typ+" (embedded)")
+			}
+			continue
+		}
+
+		tag := structTag(f)
+		comment := fieldComment(f)
+
+		for _, name := range f.Names {
+			if !name.IsExported() || slices.Contains(gormModelColumns, name.Name) {
+				continue
+			}
+			if g := strings.TrimSpace(tag.Get("gorm")); g == "-" || g == "-:all" {
+				skipped = append(skipped, name.Name+" "+typ+" (gorm:\""+g+"\")")
+				continue
+			}
+			field := Field{Name: name.Name, Comment: comment}
+			base := strings.TrimPrefix(typ, "*")
+			field.IsNullable = base != typ
+
+			switch {
+			case strings.HasSuffix(name.Name, "ID") && fieldNames[strings.TrimSuffix(name.Name, "ID")]:
+				// The foreign key of a relation; imported with it below.
+				continue
+			case structs[base]:
+				fk, ok := structField(st, name.Name+"ID")
+				if !ok {
+					skipped = append(skipped, name.Name+" "+typ+" (no "+name.Name+"ID foreign key)")
+					continue
+				}
+				// The relation is stored in its foreign key, which carries
+				// the nullability and the tags.
+				field.Type = base
+				field.TypeIsRelation = true
+				field.IsNullable = strings.HasPrefix(types.ExprString(fk.Type), "*")
+				tag = structTag(fk)
+				if field.Comment == "" {
+					field.Comment = fieldComment(fk)
+				}
+			case strings.HasPrefix(base, "[]") && structs[strings.TrimPrefix(base, "[]")]:
+				skipped = append(skipped, name.Name+" "+typ+" (has-many relation)")
+				continue
+			case enums[base] != nil:
+				field.Type = base
+				field.TypeIsEnum = true
+				usedEnums[base] = true
+			case ValidateType(base) != nil:
+				skipped = append(skipped, name.Name+" "+typ+" (unsupported type)")
+				continue
+			default:
+				field.Type = base
+			}
+
+			field.GormTag = importedGormTag(tag.Get("gorm"), field)
+			if v := tag.Get("validate"); v != "" && v != "-" {
+				field.Validation = strings.Split(v, ",")
+			}
+			config.Fields = append(config.Fields, field)
+		}
+	}
+
+	for name, values := range enums {
+		if usedEnums[name] {
+			config.Enums = append(config.Enums, Enum{Name: name, Values: values})
+		}
+	}
+	slices.SortFunc(config.Enums, func(a, b Enum) int { return strings.Compare(a.Name, b.Name) })
+	return config, skipped, nil
+}
+
+func absPath(path string) string {
+	abs, _ := filepath.Abs(path)
+	return abs
+}
+
+func structTag(f *ast.Field) reflect.StructTag {
+	if f.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(f.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+func structType(file *ast.File, name string) (*ast.StructType, error) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Name.Name != name {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("type %s is not a struct", name)
+			}
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s not found", name)
+}
+
+func structField(st *ast.StructType, name string) (*ast.Field, bool) {
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if n.Name == name {
+				return f, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// packageTypes collects the string types of files with the values of their
+// constants, and the names of their struct types.
+func packageTypes(files []*ast.File) (enums map[string][]string, structs map[string]bool) {
+	enums = make(map[string][]string)
+	structs = make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					structs[ts.Name.Name] = true
+				case *ast.Ident:
+					if t.Name == "string" {
+						enums[ts.Name.Name] = nil
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				typ, ok := vs.Type.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if _, ok := enums[typ.Name]; !ok {
+					continue
+				}
+				for _, v := range vs.Values {
+					lit, ok := v.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					if value, err := strconv.Unquote(lit.Value); err == nil {
+						enums[typ.Name] = append(enums[typ.Name], value)
+					}
+				}
+			}
+		}
+	}
+
+	// A string type without constants is just a string.
+	for name, values := range enums {
+		if len(values) == 0 {
+			delete(enums, name)
+		}
+	}
+	return enums, structs
+}
+
+// importedGormTag drops the settings of a gorm tag that the generated model
+// adds by itself: the index and foreign key of relations, the column type
+// of enums and the serializer of JSON columns.
+func importedGormTag(tag string, f Field) string {
+	var kept []string
+	for _, part := range strings.Split(tag, ";") {
+		key, _, _ := strings.Cut(part, ":")
+		switch key = strings.ToLower(strings.TrimSpace(key)); {
+		case key == "":
+			continue
+		case f.TypeIsRelation && (key == "index" || key == "foreignkey" || key == "references"):
+			continue
+		case f.TypeIsEnum && key == "type":
+			continue
+		case isJSONColumn(f.Type) && key == "serializer":
+			continue
+		}
+		kept = append(kept, strings.TrimSpace(part))
+	}
+	return strings.Join(kept, ";")
+}
+
+// fieldComment is the doc or line comment of f on a single line.
+func fieldComment(f *ast.Field) string {
+	for _, group := range []*ast.CommentGroup{f.Doc, f.Comment} {
+		if text := strings.Join(strings.Fields(group.Text()), " "); text != "" {
+			return text
+		}
+	}
+	return ""
+}
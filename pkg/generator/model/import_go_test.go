@@ -0,0 +1,64 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportGoStruct(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.go")
+	err := os.WriteFile(path, []byte(`package models
+
+import "gorm.io/gorm"
+
+type OrderStatus string
+
+const (
+	OrderStatusPending OrderStatus = "PENDING"
+	OrderStatusPaid    OrderStatus = "PAID"
+)
+
+type Order struct {
+	gorm.Model
+	Title    string `+"`"+`gorm:"size:100;not null" validate:"required"`+"`"+` // shown to customers
+	Note     *string
+	Status   OrderStatus `+"`"+`gorm:"type:varchar(20)"`+"`"+`
+	MarketID uint
+	Market   Market
+	Lines    []Line
+	Cache    string `+"`"+`gorm:"-"`+"`"+`
+	Total    int    `+"`"+`gorm:"-:all"`+"`"+`
+	internal int
+}
+
+type Market struct{ gorm.Model }
+
+type Line struct{ gorm.Model }
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, skipped, err := ImportGoStruct(path, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Field{
+		{Name: "Title", Type: "string", GormTag: "size:100;not null", Validation: []string{"required"}, Comment: "shown to customers"},
+		{Name: "Note", Type: "string", IsNullable: true},
+		{Name: "Status", Type: "OrderStatus", TypeIsEnum: true},
+		{Name: "Market", Type: "Market", TypeIsRelation: true},
+	}
+	if !reflect.DeepEqual(config.Fields, want) {
+		t.Errorf("fields = %+v, want %+v", config.Fields, want)
+	}
+	if want := []Enum{{Name: "OrderStatus", Values: []string{"PENDING", "PAID"}}}; !reflect.DeepEqual(config.Enums, want) {
+		t.Errorf("enums = %+v, want %+v", config.Enums, want)
+	}
+	wantSkipped := []string{"Lines []Line (has-many relation)", `Cache string (gorm:"-")`, `Total int (gorm:"-:all")`}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped = %q, want %q", skipped, wantSkipped)
+	}
+}
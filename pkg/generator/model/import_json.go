@@ -0,0 +1,375 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// jsonObject is a decoded JSON object that keeps its keys in order.
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// decodeJSON decodes the next value of dec into nil, bool, json.Number,
+// string, []any or *jsonObject.
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &jsonObject{values: make(map[string]any)}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			k := key.(string)
+			if _, ok := obj.values[k]; !ok {
+				obj.keys = append(obj.keys, k)
+			}
+			obj.values[k] = value
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+// enumCandidate matches string values that read like enum values.
+var enumCandidate = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
+// maxEnumValues is the most distinct values a string field may have to be
+// taken for an enum.
+const maxEnumValues = 10
+
+// ImportJSON infers models from a sample payload: a JSON object, or an
+// array of objects of the same resource. name is the model of the payload
+// itself:
+//
+//   - keys become fields, e.g. customer_email or customerEmail becomes
+//     CustomerEmail
+//   - RFC 3339 strings become time.Time, numbers int or float64, arrays of
+//     scalars slices such as []int64
+//   - keys missing from some objects or null somewhere become nullable
+//   - a nested object becomes a model of its own and a relation
+//   - string values that repeat across the objects become an enum of the
+//     values seen, as a candidate for the user to confirm
+//
+// The gorm.Model keys id, created_at, updated_at and deleted_at are
+// skipped, and listed in skipped when their type differs from gorm.Model's.
+// Keys with no equivalent in the generator, such as arrays of objects, are
+// skipped and listed too. Module and output path are left for the caller
+// to fill in.
+func ImportJSON(data []byte, name string) (configs []*ModelConfig, skipped []string, err error) {
+	if err := ValidateName("model", name); err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeJSON(dec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, errors.New("expected a single JSON value")
+	}
+
+	var objects []*jsonObject
+	switch v := value.(type) {
+	case *jsonObject:
+		objects = []*jsonObject{v}
+	case []any:
+		for _, item := range v {
+			obj, ok := item.(*jsonObject)
+			if !ok {
+				return nil, nil, errors.New("expected an array of objects")
+			}
+			objects = append(objects, obj)
+		}
+	}
+	if len(objects) == 0 {
+		return nil, nil, errors.New("expected an object or a non-empty array of objects")
+	}
+
+	// Objects nested under the same key anywhere in the payload are samples
+	// of the same model.
+	var order []string
+	samples := make(map[string][]*jsonObject)
+	var collect func(model string, objects []*jsonObject)
+	collect = func(model string, objects []*jsonObject) {
+		if _, ok := samples[model]; !ok {
+			order = append(order, model)
+		}
+		samples[model] = append(samples[model], objects...)
+		for _, key := range jsonKeys(objects) {
+			var nested []*jsonObject
+			for _, obj := range objects {
+				if v, ok := obj.values[key].(*jsonObject); ok {
+					nested = append(nested, v)
+				}
+			}
+			if len(nested) > 0 && toPascal(key) != model && ValidateName("model", toPascal(key)) == nil {
+				collect(toPascal(key), nested)
+			}
+		}
+	}
+	collect(name, objects)
+
+	models := make(map[string]*ModelConfig)
+	for _, model := range order {
+		config, notes := jsonModel(model, samples[model], samples)
+		skipped = append(skipped, notes...)
+		models[model] = config
+	}
+
+	// A nested object without fields, e.g. {}, is no model, and neither is
+	// an object whose only fields were such relations.
+	for dropped := true; dropped; {
+		dropped = false
+		for _, config := range models {
+			config.Fields = slices.DeleteFunc(config.Fields, func(f Field) bool {
+				if f.TypeIsRelation && len(models[f.Type].Fields) == 0 {
+					skipped = append(skipped, fmt.Sprintf("%s.%s: object without fields", config.ModelName, f.Name))
+					dropped = true
+					return true
+				}
+				return false
+			})
+		}
+	}
+	for _, model := range order {
+		if len(models[model].Fields) > 0 {
+			configs = append(configs, models[model])
+		}
+	}
+	if len(configs) == 0 || configs[0].ModelName != name {
+		return nil, skipped, fmt.Errorf("no fields found for %s", name)
+	}
+	return configs, skipped, nil
+}
+
+// jsonKeys returns the keys of objects in the order they first appear.
+func jsonKeys(objects []*jsonObject) []string {
+	var keys []string
+	for _, obj := range objects {
+		for _, k := range obj.keys {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+func jsonModel(name string, objects []*jsonObject, models map[string][]*jsonObject) (*ModelConfig, []string) {
+	var skipped []string
+	config := &ModelConfig{ModelName: name}
+	keys := jsonKeys(objects)
+	fieldNames := make(map[string]bool)
+	for _, k := range keys {
+		fieldNames[toPascal(k)] = true
+	}
+
+	for _, key := range keys {
+		fieldName := toPascal(key)
+		where := name + "." + key
+		if slices.Contains([]string{"Id", "ID", "CreatedAt", "UpdatedAt", "DeletedAt"}, fieldName) {
+			// The generated model has the field from gorm.Model; a key of
+			// another type, such as a string id, is worth a note.
+			if mismatch := gormModelMismatch(fieldName, jsonGoType(objects, key)); mismatch != "" {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", where, mismatch))
+			}
+			continue
+		}
+		// The foreign key next to a nested object is imported with it.
+		if base := strings.TrimSuffix(strings.TrimSuffix(fieldName, "ID"), "Id"); base != fieldName && fieldNames[base] {
+			continue
+		}
+		if err := ValidateName("field", fieldName); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: not a valid Go name", where))
+			continue
+		}
+		if slices.ContainsFunc(config.Fields, func(f Field) bool { return f.Name == fieldName }) {
+			skipped = append(skipped, fmt.Sprintf("%s: another key is also named %s", where, fieldName))
+			continue
+		}
+
+		var values []any
+		field := Field{Name: fieldName}
+		for _, obj := range objects {
+			v, ok := obj.values[key]
+			if !ok || v == nil {
+				field.IsNullable = true
+				continue
+			}
+			values = append(values, v)
+		}
+
+		kind := jsonKind(values)
+		switch {
+		case kind == "object" && fieldName == name:
+			skipped = append(skipped, fmt.Sprintf("%s: nested %s in itself", where, name))
+			continue
+		case kind == "object" && models[fieldName] != nil:
+			field.Type, field.TypeIsRelation = fieldName, true
+		case kind == "object":
+			skipped = append(skipped, fmt.Sprintf("%s: nested object without a valid model name", where))
+			continue
+		case kind == "string":
+			strs := jsonStrings(values)
+			if jsonTimes(strs) {
+				field.Type = "time.Time"
+			} else if enum, ok := jsonEnum(name+fieldName, strs); ok {
+				config.Enums = append(config.Enums, enum)
+				field.Type, field.TypeIsEnum = enum.Name, true
+			} else {
+				field.Type = "string"
+			}
+		case kind == "":
+			// Only nulls: the type cannot be told, string is a guess.
+			field.Type = "string"
+		case kind == "array":
+			var items []any
+			for _, v := range values {
+				items = append(items, v.([]any)...)
+			}
+			switch elem := jsonKind(items); elem {
+			case "int":
+				field.Type = "[]int64"
+			case "string", "float64", "bool":
+				field.Type = "[]" + elem
+			case "object":
+				skipped = append(skipped, fmt.Sprintf("%s: array of objects (has-many relation)", where))
+				continue
+			case "array":
+				skipped = append(skipped, fmt.Sprintf("%s: array of arrays", where))
+				continue
+			case "":
+				skipped = append(skipped, fmt.Sprintf("%s: empty array, the element type cannot be told", where))
+				continue
+			default:
+				skipped = append(skipped, fmt.Sprintf("%s: array of mixed types", where))
+				continue
+			}
+		case kind == "mixed":
+			skipped = append(skipped, fmt.Sprintf("%s: mixed types", where))
+			continue
+		default:
+			field.Type = kind
+		}
+		config.Fields = append(config.Fields, field)
+	}
+	return config, skipped
+}
+
+// jsonKind returns the Go type shared by values: string, int, float64 or
+// bool, or object, array, "" when there are no values and mixed when they
+// differ. Integers mixed with fractions are float64.
+func jsonKind(values []any) string {
+	kind := ""
+	for _, v := range values {
+		var k string
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			k = "string"
+		case bool:
+			k = "bool"
+		case json.Number:
+			k = "float64"
+			if _, err := v.Int64(); err == nil {
+				k = "int"
+			}
+		case *jsonObject:
+			k = "object"
+		case []any:
+			k = "array"
+		}
+		switch {
+		case kind == "" || kind == k:
+			kind = k
+		case (kind == "int" && k == "float64") || (kind == "float64" && k == "int"):
+			kind = "float64"
+		default:
+			return "mixed"
+		}
+	}
+	return kind
+}
+
+// jsonGoType returns the scalar Go type of the values of key, or "" when
+// there are none.
+func jsonGoType(objects []*jsonObject, key string) string {
+	var values []any
+	for _, obj := range objects {
+		values = append(values, obj.values[key])
+	}
+	kind := jsonKind(values)
+	if kind == "string" && jsonTimes(jsonStrings(slices.DeleteFunc(values, func(v any) bool { return v == nil }))) {
+		return "time.Time"
+	}
+	return kind
+}
+
+func jsonStrings(values []any) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i], _ = v.(string)
+	}
+	return strs
+}
+
+// jsonTimes reports whether all values are RFC 3339 timestamps.
+func jsonTimes(values []string) bool {
+	for _, v := range values {
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+// jsonEnum returns the enum candidate of a string field: its values repeat,
+// each one seen twice on average, so x, y, y is no enum, there are only a
+// few of them and they read like enum values.
+func jsonEnum(name string, values []string) (Enum, bool) {
+	var distinct []string
+	for _, v := range values {
+		if !enumCandidate.MatchString(v) {
+			return Enum{}, false
+		}
+		if !slices.Contains(distinct, v) {
+			distinct = append(distinct, v)
+		}
+	}
+	if len(values) < 2*len(distinct) || len(distinct) > maxEnumValues {
+		return Enum{}, false
+	}
+	return Enum{Name: name, Values: distinct}, true
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []Field
+		enums   []Enum
+		skipped []string
+	}{
+		{
+			name:    "scalars",
+			payload: `{"title": "a", "customerEmail": "a@b.c", "total": 3, "price": 1.5, "paid": true, "due_at": "2024-01-02T03:04:05Z"}`,
+			want: []Field{
+				{Name: "Title", Type: "string"},
+				{Name: "CustomerEmail", Type: "string"},
+				{Name: "Total", Type: "int"},
+				{Name: "Price", Type: "float64"},
+				{Name: "Paid", Type: "bool"},
+				{Name: "DueAt", Type: "time.Time"},
+			},
+		},
+		{
+			name:    "integers mixed with fractions",
+			payload: `[{"price": 1}, {"price": 1.5}]`,
+			want:    []Field{{Name: "Price", Type: "float64"}},
+		},
+		{
+			name:    "missing and null keys",
+			payload: `[{"title": "a", "note": null}, {"title": "b", "note": "x", "code": "c"}]`,
+			want: []Field{
+				{Name: "Title", Type: "string"},
+				{Name: "Note", Type: "string", IsNullable: true},
+				{Name: "Code", Type: "string", IsNullable: true},
+			},
+		},
+		{
+			name:    "arrays",
+			payload: `{"tags": ["a"], "counts": [1, 2], "lines": [{"sku": "x"}], "empty": [], "mixed": [1, "a"]}`,
+			want:    []Field{{Name: "Tags", Type: "[]string"}, {Name: "Counts", Type: "[]int64"}},
+			skipped: []string{
+				"Order.lines: array of objects (has-many relation)",
+				"Order.empty: empty array, the element type cannot be told",
+				"Order.mixed: array of mixed types",
+			},
+		},
+		{
+			name:    "enum",
+			payload: `[{"status": "paid"}, {"status": "pending"}, {"status": "paid"}, {"status": "pending"}]`,
+			want:    []Field{{Name: "Status", Type: "OrderStatus", TypeIsEnum: true}},
+			enums:   []Enum{{Name: "OrderStatus", Values: []string{"paid", "pending"}}},
+		},
+		{
+			name:    "value seen twice is no enum",
+			payload: `[{"name": "x"}, {"name": "y"}, {"name": "y"}]`,
+			want:    []Field{{Name: "Name", Type: "string"}},
+		},
+		{
+			name:    "distinct values are no enum",
+			payload: `[{"status": "paid"}, {"status": "pending"}]`,
+			want:    []Field{{Name: "Status", Type: "string"}},
+		},
+		{
+			name:    "values that do not read like enum values",
+			payload: `[{"note": "two words"}, {"note": "two words"}]`,
+			want:    []Field{{Name: "Note", Type: "string"}},
+		},
+		{
+			name:    "gorm.Model keys",
+			payload: `{"id": 1, "created_at": "2024-01-02T03:04:05Z", "updatedAt": null, "title": "a"}`,
+			want:    []Field{{Name: "Title", Type: "string"}},
+		},
+		{
+			name:    "gorm.Model keys of another type",
+			payload: `{"id": "ord_1", "created_at": 1700000000, "title": "a"}`,
+			want:    []Field{{Name: "Title", Type: "string"}},
+			skipped: []string{
+				"Order.id: string, while gorm.Model's ID is uint",
+				"Order.created_at: int, while gorm.Model's CreatedAt is time.Time",
+			},
+		},
+		{
+			name:    "mixed types",
+			payload: `[{"title": "a", "code": 1}, {"title": "b", "code": "x"}]`,
+			want:    []Field{{Name: "Title", Type: "string"}},
+			skipped: []string{"Order.code: mixed types"},
+		},
+		{
+			name:    "keys with the same field name",
+			payload: `{"title": "a", "Title": "b", "2nd": "c"}`,
+			want:    []Field{{Name: "Title", Type: "string"}},
+			skipped: []string{"Order.Title: another key is also named Title", "Order.2nd: not a valid Go name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, skipped, err := ImportJSON([]byte(tt.payload), "Order")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(configs) != 1 {
+				t.Fatalf("got %d models, want 1", len(configs))
+			}
+			if !reflect.DeepEqual(configs[0].Fields, tt.want) {
+				t.Errorf("fields = %+v\nwant %+v", configs[0].Fields, tt.want)
+			}
+			if !reflect.DeepEqual(configs[0].Enums, tt.enums) {
+				t.Errorf("enums = %+v, want %+v", configs[0].Enums, tt.enums)
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestImportJSONNested(t *testing.T) {
+	configs, skipped, err := ImportJSON([]byte(`[
+  {"title": "a", "market": {"id": 1, "name": "x"}, "market_id": 1, "meta": {}},
+  {"title": "b", "market": {"name": "y", "region": "eu"}}
+]`), "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("got %d models, want 2", len(configs))
+	}
+	want := []Field{{Name: "Title", Type: "string"}, {Name: "Market", Type: "Market", TypeIsRelation: true}}
+	if !reflect.DeepEqual(configs[0].Fields, want) {
+		t.Errorf("Order fields = %+v, want %+v", configs[0].Fields, want)
+	}
+	// Both samples of the nested object describe Market.
+	want = []Field{{Name: "Name", Type: "string"}, {Name: "Region", Type: "string", IsNullable: true}}
+	if !reflect.DeepEqual(configs[1].Fields, want) {
+		t.Errorf("Market fields = %+v, want %+v", configs[1].Fields, want)
+	}
+	if want := []string{"Order.Meta: object without fields"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %q, want %q", skipped, want)
+	}
+}
+
+func TestImportJSONErrors(t *testing.T) {
+	tests := []struct {
+		payload string
+		name    string
+		want    string
+	}{
+		{`{"title": "a"}`, "order", "model"},
+		{`{"title": "a"} {}`, "Order", "single JSON value"},
+		{`[1, 2]`, "Order", "array of objects"},
+		{`[]`, "Order", "non-empty array"},
+		{`{"id": 1}`, "Order", "no fields found for Order"},
+		{`{"title": `, "Order", "EOF"},
+	}
+	for _, tt := range tests {
+		_, _, err := ImportJSON([]byte(tt.payload), tt.name)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ImportJSON(%q, %q) = %v, want an error containing %q", tt.payload, tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISchema is the part of an OpenAPI 3 schema object the importer
+// understands.
+type openAPISchema struct {
+	Ref         string           `yaml:"$ref"`
+	Type        yaml.Node        `yaml:"type"` // a name, or a list of names in OpenAPI 3.1
+	Format      string           `yaml:"format"`
+	Enum        []any            `yaml:"enum"`
+	Nullable    bool             `yaml:"nullable"`
+	Required    []string         `yaml:"required"`
+	Properties  yaml.Node        `yaml:"properties"`
+	Additional  yaml.Node        `yaml:"additionalProperties"` // a schema, or true or false
+	Items       *openAPISchema   `yaml:"items"`
+	AllOf       []*openAPISchema `yaml:"allOf"`
+	AnyOf       []*openAPISchema `yaml:"anyOf"`
+	OneOf       []*openAPISchema `yaml:"oneOf"`
+	MinLength   *int             `yaml:"minLength"`
+	MaxLength   *int             `yaml:"maxLength"`
+	Minimum     *float64         `yaml:"minimum"`
+	Maximum     *float64         `yaml:"maximum"`
+	Description string           `yaml:"description"`
+}
+
+// types returns the type names of s; OpenAPI 3.1 lists "null" among them
+// for nullable schemas.
+func (s *openAPISchema) types() []string {
+	switch s.Type.Kind {
+	case yaml.ScalarNode:
+		return []string{s.Type.Value}
+	case yaml.SequenceNode:
+		var names []string
+		for _, n := range s.Type.Content {
+			names = append(names, n.Value)
+		}
+		return names
+	}
+	return nil
+}
+
+// typ returns the type of s other than "null".
+func (s *openAPISchema) typ() string {
+	for _, t := range s.types() {
+		if t != "null" {
+			return t
+		}
+	}
+	if s.Properties.Kind == yaml.MappingNode {
+		return "object"
+	}
+	return ""
+}
+
+// unwrap returns the schema behind the allOf/anyOf/oneOf wrappers used for
+// nullable references, such as anyOf: [{$ref: ...}, {type: "null"}], and
+// whether the wrapper allows null.
+func (s *openAPISchema) unwrap() (*openAPISchema, bool) {
+	nullable := s.Nullable || slices.Contains(s.types(), "null")
+	for _, list := range [][]*openAPISchema{s.AllOf, s.AnyOf, s.OneOf} {
+		var inner []*openAPISchema
+		for _, sub := range list {
+			if slices.Contains(sub.types(), "null") && sub.Ref == "" {
+				nullable = true
+				continue
+			}
+			inner = append(inner, sub)
+		}
+		if len(inner) == 1 {
+			return inner[0], nullable
+		}
+	}
+	return s, nullable
+}
+
+// properties returns the properties of s in document order.
+func (s *openAPISchema) properties() ([]string, []*openAPISchema, error) {
+	var names []string
+	var schemas []*openAPISchema
+	for i := 0; i+1 < len(s.Properties.Content); i += 2 {
+		var prop openAPISchema
+		if err := s.Properties.Content[i+1].Decode(&prop); err != nil {
+			return nil, nil, err
+		}
+		names = append(names, s.Properties.Content[i].Value)
+		schemas = append(schemas, &prop)
+	}
+	return names, schemas, nil
+}
+
+type openAPIOperation struct {
+	RequestBody struct {
+		Content map[string]struct {
+			Schema *openAPISchema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *openAPISchema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+// refs returns the component schemas the operation sends or returns.
+func (o *openAPIOperation) refs() []string {
+	var schemas []*openAPISchema
+	for _, c := range o.RequestBody.Content {
+		schemas = append(schemas, c.Schema)
+	}
+	for code, r := range o.Responses {
+		if strings.HasPrefix(code, "2") {
+			for _, c := range r.Content {
+				schemas = append(schemas, c.Schema)
+			}
+		}
+	}
+	var refs []string
+	for _, s := range schemas {
+		if s == nil {
+			continue
+		}
+		if s.Items != nil {
+			s = s.Items
+		}
+		if s, _ = s.unwrap(); s.Ref != "" {
+			refs = append(refs, refName(s.Ref))
+		}
+	}
+	return refs
+}
+
+type openAPIDocument struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Paths      map[string]yaml.Node `yaml:"paths"`
+	Components struct {
+		Schemas yaml.Node `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// ImportOpenAPI builds models from the component schemas of the OpenAPI 3
+// document at path, in YAML or JSON:
+//
+//   - object schemas become models and their properties fields, nullable
+//     when the schema says so
+//   - string enums, inline or as their own schema, become enums
+//   - a $ref to another object schema becomes a relation
+//   - the formats date-time, uuid and decimal become time.Time, uuid.UUID
+//     and decimal.Decimal, additionalProperties maps and free-form objects
+//     json.RawMessage
+//   - required, format, minLength/maxLength and minimum/maximum become
+//     validations
+//   - the paths of each model select its operations, e.g. GET /orders/{id}
+//     selects get; a model without paths gets every operation
+//
+// The gorm.Model properties id, createdAt, updatedAt and deletedAt are
+// skipped, and listed in skipped when their type differs from gorm.Model's.
+// Properties with no equivalent, such as arrays of objects, are skipped
+// and listed too. Module and output path are left for the caller to fill
+// in.
+func ImportOpenAPI(path string) (configs []*ModelConfig, skipped []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, nil, fmt.Errorf("%s: not an OpenAPI 3 document", path)
+	}
+
+	names, schemas := []string{}, map[string]*openAPISchema{}
+	for i := 0; i+1 < len(doc.Components.Schemas.Content); i += 2 {
+		var s openAPISchema
+		if err := doc.Components.Schemas.Content[i+1].Decode(&s); err != nil {
+			return nil, nil, fmt.Errorf("%s: schema %s: %w", path, doc.Components.Schemas.Content[i].Value, err)
+		}
+		name := doc.Components.Schemas.Content[i].Value
+		names = append(names, name)
+		schemas[name] = &s
+	}
+
+	models := make(map[string]bool)
+	for _, name := range names {
+		if schemas[name].typ() == "object" {
+			models[name] = true
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, name := range names {
+		if !models[name] {
+			continue
+		}
+		config, notes, err := openAPIModel(name, schemas, models, declared)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: schema %s: %w", path, name, err)
+		}
+		skipped = append(skipped, notes...)
+		if len(config.Fields) == 0 {
+			skipped = append(skipped, name+": no fields to import")
+			continue
+		}
+		configs = append(configs, config)
+	}
+
+	ops, err := openAPIOperations(doc.Paths, configs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, config := range configs {
+		for _, op := range AllOperations {
+			if ops[config.ModelName][op] {
+				config.Operations = append(config.Operations, op)
+			}
+		}
+	}
+	return configs, skipped, nil
+}
+
+// openAPIModel maps the object schema name to a model. Enums are declared
+// in the first model using them; declared tracks them across models.
+func openAPIModel(name string, schemas map[string]*openAPISchema, models, declared map[string]bool) (*ModelConfig, []string, error) {
+	var skipped []string
+	config := &ModelConfig{ModelName: toPascal(name)}
+	schema := schemas[name]
+	propNames, props, err := schema.properties()
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldNames := make(map[string]bool)
+	for _, p := range propNames {
+		fieldNames[toPascal(p)] = true
+	}
+
+	for i, prop := range props {
+		fieldName := toPascal(propNames[i])
+		if slices.Contains([]string{"Id", "ID", "CreatedAt", "UpdatedAt", "DeletedAt"}, fieldName) {
+			// The generated model has the field from gorm.Model; a property
+			// of another type, such as a string id, is worth a note.
+			if inner, _ := prop.unwrap(); inner.Ref == "" {
+				if mismatch := gormModelMismatch(fieldName, openAPIGoType(inner)); mismatch != "" {
+					skipped = append(skipped, fmt.Sprintf("%s.%s: %s", name, propNames[i], mismatch))
+				}
+			}
+			continue
+		}
+		// The foreign key next to a relation is imported with it.
+		if base := strings.TrimSuffix(strings.TrimSuffix(fieldName, "ID"), "Id"); base != fieldName && fieldNames[base] {
+			continue
+		}
+
+		inner, nullable := prop.unwrap()
+		field := Field{Name: fieldName, IsNullable: nullable, Comment: strings.Join(strings.Fields(prop.Description), " ")}
+		if field.Comment == "" {
+			field.Comment = strings.Join(strings.Fields(inner.Description), " ")
+		}
+		where := name + "." + propNames[i]
+
+		if inner.Ref != "" {
+			ref := refName(inner.Ref)
+			target := schemas[ref]
+			switch {
+			case target == nil:
+				skipped = append(skipped, fmt.Sprintf("%s: unknown schema %s", where, ref))
+				continue
+			case models[ref] && ref == name:
+				skipped = append(skipped, fmt.Sprintf("%s: reference to its own schema", where))
+				continue
+			case models[ref]:
+				field.Type, field.TypeIsRelation = toPascal(ref), true
+			case len(target.Enum) > 0:
+				inner = target
+				field.Type = toPascal(ref)
+			default:
+				inner = target
+			}
+		}
+
+		if !field.TypeIsRelation {
+			if len(inner.Enum) > 0 {
+				enum := Enum{Name: field.Type}
+				if enum.Name == "" || declared[enum.Name] {
+					enum.Name = config.ModelName + fieldName
+				}
+				for _, v := range inner.Enum {
+					if v != nil {
+						enum.Values = append(enum.Values, fmt.Sprint(v))
+					}
+				}
+				declared[enum.Name] = true
+				config.Enums = append(config.Enums, enum)
+				field.Type, field.TypeIsEnum = enum.Name, true
+			} else if field.Type = openAPIGoType(inner); field.Type == "" {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", where, openAPIDescribe(inner)))
+				continue
+			}
+			if field.Type != "decimal.Decimal" {
+				field.Validation = openAPIValidation(inner)
+			}
+			if inner.MaxLength != nil && field.Type == "string" {
+				field.GormTag = "size:" + strconv.Itoa(*inner.MaxLength)
+			}
+		}
+		if slices.Contains(schema.Required, propNames[i]) {
+			field.Validation = append([]string{"required"}, field.Validation...)
+		}
+		config.Fields = append(config.Fields, field)
+	}
+	return config, skipped, nil
+}
+
+// openAPIGoType returns the Go type of a schema, or "" when it has none.
+func openAPIGoType(s *openAPISchema) string {
+	switch s.typ() {
+	case "string":
+		switch s.Format {
+		case "date-time", "date":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		case "uuid":
+			return "uuid.UUID"
+		case "decimal":
+			return "decimal.Decimal"
+		}
+		return "string"
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		switch s.Format {
+		case "float":
+			return "float32"
+		case "decimal":
+			return "decimal.Decimal"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		if s.Items == nil || s.Items.Ref != "" {
+			return ""
+		}
+		if elem := openAPIElemType(s.Items); elem != "" {
+			return "[]" + elem
+		}
+	case "object":
+		if s.Properties.Kind == yaml.MappingNode && len(s.Properties.Content) > 0 {
+			return ""
+		}
+		if s.Additional.Kind != yaml.MappingNode {
+			// A free-form object.
+			return "json.RawMessage"
+		}
+		var value openAPISchema
+		if err := s.Additional.Decode(&value); err == nil && value.Ref == "" {
+			if elem := openAPIElemType(&value); elem != "" {
+				return "map[string]" + elem
+			}
+		}
+	}
+	return ""
+}
+
+// openAPIElemType returns the Go type of the elements of an array or the
+// values of a map, or "" when it has none the generator can store.
+func openAPIElemType(s *openAPISchema) string {
+	if s.Ref != "" {
+		return ""
+	}
+	elem := openAPIGoType(s)
+	if elem == "int" {
+		elem = "int64"
+	}
+	if ValidateType("[]"+elem) != nil {
+		return ""
+	}
+	return elem
+}
+
+func openAPIDescribe(s *openAPISchema) string {
+	if s.typ() == "array" && s.Items != nil && s.Items.Ref != "" {
+		return "array of " + refName(s.Items.Ref) + " (has-many relation)"
+	}
+	if t := s.typ(); t != "" {
+		return "unsupported " + t + " property"
+	}
+	return "property without a type"
+}
+
+// openAPIValidation maps format and the length and range keywords of s to
+// validate tags.
+func openAPIValidation(s *openAPISchema) []string {
+	var rules []string
+	switch s.Format {
+	case "email":
+		rules = append(rules, "email")
+	case "uri", "url":
+		rules = append(rules, "url")
+	case "ipv4":
+		rules = append(rules, "ipv4")
+	case "ipv6":
+		rules = append(rules, "ipv6")
+	}
+	if s.MinLength != nil {
+		rules = append(rules, "min="+strconv.Itoa(*s.MinLength))
+	}
+	if s.MaxLength != nil {
+		rules = append(rules, "max="+strconv.Itoa(*s.MaxLength))
+	}
+	if s.Minimum != nil {
+		rules = append(rules, "gte="+strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+	}
+	if s.Maximum != nil {
+		rules = append(rules, "lte="+strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+	}
+	return rules
+}
+
+// openAPIOperations maps the operations of paths to the CRUD operations of
+// the models: POST and GET on a collection such as /orders are create and
+// list, GET, PUT, PATCH and DELETE on an item such as /orders/{id} are get,
+// update, patch and delete. The model is the one named by the collection,
+// or else the one the operation sends or returns. Actions on an item, such
+// as POST /orders/{id}/cancel, are none of them and are left out.
+func openAPIOperations(paths map[string]yaml.Node, configs []*ModelConfig) (map[string]map[Operation]bool, error) {
+	ops := make(map[string]map[Operation]bool)
+	for path, node := range paths {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		item := len(segments) > 1 && strings.HasPrefix(segments[len(segments)-1], "{")
+		if item {
+			segments = segments[:len(segments)-1]
+		}
+		collection := segments[len(segments)-1]
+		// Below an item, as in /orders/{id}/cancel, the last segment is an
+		// action unless it names a model, as in /markets/{id}/orders.
+		nested := slices.ContainsFunc(segments, func(s string) bool { return strings.HasPrefix(s, "{") })
+
+		var methods map[string]yaml.Node
+		if err := node.Decode(&methods); err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		for method, opNode := range methods {
+			var op Operation
+			switch method = strings.ToLower(method); {
+			case method == "post" && !item:
+				op = OpCreate
+			case method == "get" && !item:
+				op = OpList
+			case method == "get":
+				op = OpGet
+			case method == "put" && item:
+				op = OpUpdate
+			case method == "patch" && item:
+				op = OpPatch
+			case method == "delete" && item:
+				op = OpDelete
+			default:
+				continue
+			}
+
+			model := ""
+			for _, config := range configs {
+				if modelName(snake(strings.ReplaceAll(collection, "-", "_"))) == config.ModelName {
+					model = config.ModelName
+				}
+			}
+			if model == "" && !nested {
+				var operation openAPIOperation
+				if err := opNode.Decode(&operation); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+				}
+				for _, ref := range operation.refs() {
+					for _, config := range configs {
+						if toPascal(ref) == config.ModelName {
+							model = config.ModelName
+						}
+					}
+				}
+			}
+			if model == "" {
+				continue
+			}
+			if ops[model] == nil {
+				ops[model] = make(map[Operation]bool)
+			}
+			ops[model][op] = true
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeOpenAPI(t *testing.T, doc string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportOpenAPI(t *testing.T) {
+	path := writeOpenAPI(t, `
+openapi: 3.1.0
+components:
+  schemas:
+    OrderStatus:
+      type: string
+      enum: [PENDING, PAID]
+    Market:
+      type: object
+      properties:
+        name: {type: string}
+    Order:
+      type: object
+      required: [title]
+      properties:
+        id: {type: integer, format: int64}
+        createdAt: {type: string, format: date-time}
+        title: {type: string, maxLength: 100, description: Shown to customers}
+        email: {type: string, format: email}
+        status: {$ref: '#/components/schemas/OrderStatus'}
+        priority: {type: string, enum: [low, high]}
+        market: {$ref: '#/components/schemas/Market'}
+        marketId: {type: integer}
+        note: {type: [string, "null"]}
+        total: {type: string, format: decimal}
+        ref: {type: string, format: uuid}
+        quantity: {type: integer, minimum: 1, maximum: 99}
+        tags: {type: array, items: {type: integer}}
+        labels: {type: object, additionalProperties: {type: string}}
+        metadata: {type: object}
+        lines: {type: array, items: {$ref: '#/components/schemas/Market'}}
+`)
+	configs, skipped, err := ImportOpenAPI(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("got %d models, want 2", len(configs))
+	}
+	order := configs[1]
+	want := []Field{
+		{Name: "Title", Type: "string", GormTag: "size:100", Validation: []string{"required", "max=100"}, Comment: "Shown to customers"},
+		{Name: "Email", Type: "string", Validation: []string{"email"}},
+		{Name: "Status", Type: "OrderStatus", TypeIsEnum: true},
+		{Name: "Priority", Type: "OrderPriority", TypeIsEnum: true},
+		{Name: "Market", Type: "Market", TypeIsRelation: true},
+		{Name: "Note", Type: "string", IsNullable: true},
+		{Name: "Total", Type: "decimal.Decimal"},
+		{Name: "Ref", Type: "uuid.UUID"},
+		{Name: "Quantity", Type: "int", Validation: []string{"gte=1", "lte=99"}},
+		{Name: "Tags", Type: "[]int64"},
+		{Name: "Labels", Type: "map[string]string"},
+		{Name: "Metadata", Type: "json.RawMessage"},
+	}
+	if !reflect.DeepEqual(order.Fields, want) {
+		t.Errorf("fields = %+v\nwant %+v", order.Fields, want)
+	}
+	wantEnums := []Enum{{Name: "OrderStatus", Values: []string{"PENDING", "PAID"}}, {Name: "OrderPriority", Values: []string{"low", "high"}}}
+	if !reflect.DeepEqual(order.Enums, wantEnums) {
+		t.Errorf("enums = %+v, want %+v", order.Enums, wantEnums)
+	}
+	if want := []string{"Order.lines: array of Market (has-many relation)"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %q, want %q", skipped, want)
+	}
+}
+
+func TestImportOpenAPIGormModelProperties(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   string
+		skipped []string
+	}{
+		{name: "matching types", props: "id: {type: integer}\n        created_at: {type: string, format: date-time}"},
+		{name: "no type", props: "id: {}"},
+		{
+			name:    "string id",
+			props:   "id: {type: string, format: uuid}",
+			skipped: []string{"Order.id: uuid.UUID, while gorm.Model's ID is uint"},
+		},
+		{
+			name:    "timestamp as a number",
+			props:   "updatedAt: {type: integer}",
+			skipped: []string{"Order.updatedAt: int, while gorm.Model's UpdatedAt is time.Time"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, skipped, err := ImportOpenAPI(writeOpenAPI(t, `
+openapi: 3.0.3
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        title: {type: string}
+        `+tt.props+`
+`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []Field{{Name: "Title", Type: "string"}}; !reflect.DeepEqual(configs[0].Fields, want) {
+				t.Errorf("fields = %+v, want %+v", configs[0].Fields, want)
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestOpenAPIOperations(t *testing.T) {
+	order := func(method, status string) string {
+		return "    " + method + ":\n      responses:\n        '" + status + "':\n          content:\n            application/json:\n              schema: {$ref: '#/components/schemas/Order'}\n"
+	}
+	tests := []struct {
+		name  string
+		paths string
+		want  []Operation
+	}{
+		{
+			name:  "collection and item",
+			paths: "  /orders:\n" + order("get", "200") + order("post", "201") + "  /orders/{id}:\n" + order("get", "200") + order("put", "200") + order("patch", "200") + order("delete", "204"),
+			want:  []Operation{OpCreate, OpGet, OpList, OpUpdate, OpPatch, OpDelete},
+		},
+		{
+			name:  "prefixed paths",
+			paths: "  /api/v1/orders:\n" + order("get", "200") + "  /api/v1/orders/{orderId}:\n" + order("delete", "204"),
+			want:  []Operation{OpList, OpDelete},
+		},
+		{
+			name:  "collection named otherwise",
+			paths: "  /purchases:\n" + order("post", "201"),
+			want:  []Operation{OpCreate},
+		},
+		{
+			name:  "nested collection",
+			paths: "  /markets/{id}/orders:\n" + order("post", "201"),
+			want:  []Operation{OpCreate},
+		},
+		{
+			name:  "action on an item",
+			paths: "  /orders/{id}:\n" + order("get", "200") + "  /orders/{id}/cancel:\n" + order("post", "200") + order("get", "200"),
+			want:  []Operation{OpGet},
+		},
+		{
+			name:  "error responses only",
+			paths: "  /purchases:\n" + order("post", "400"),
+		},
+		{
+			name:  "put on a collection",
+			paths: "  /orders:\n" + order("put", "200"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, _, err := ImportOpenAPI(writeOpenAPI(t, `
+openapi: 3.0.3
+paths:
+`+tt.paths+`
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        title: {type: string}
+`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := configs[0].Operations; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("operations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportOpenAPIErrors(t *testing.T) {
+	for _, doc := range []string{
+		"swagger: '2.0'\n",
+		"openapi: 3.0.3\ncomponents:\n  schemas:\n    Order:\n      type: object\n      properties:\n        title: 5\n",
+	} {
+		if _, _, err := ImportOpenAPI(writeOpenAPI(t, doc)); err == nil || !strings.Contains(err.Error(), "api.yaml") {
+			t.Errorf("ImportOpenAPI(%q) = %v, want an error naming the file", doc, err)
+		}
+	}
+}
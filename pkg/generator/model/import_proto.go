@@ -0,0 +1,562 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// protoScalarTypes maps the protobuf scalar types to Go types, the inverse
+// of protobufType.
+var protoScalarTypes = map[string]string{
+	"string":   "string",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"bool":     "bool",
+	"float":    "float32",
+	"double":   "float64",
+	"bytes":    "[]byte",
+}
+
+// protoWellKnownTypes maps the well-known message types to Go types. The
+// wrappers make the field nullable.
+var protoWellKnownTypes = map[string]struct {
+	goType   string
+	nullable bool
+}{
+	"google.protobuf.Timestamp":   {"time.Time", false},
+	"google.protobuf.Duration":    {"time.Duration", false},
+	"google.protobuf.StringValue": {"string", true},
+	"google.protobuf.Int64Value":  {"int64", true},
+	"google.protobuf.Int32Value":  {"int32", true},
+	"google.protobuf.UInt64Value": {"uint64", true},
+	"google.protobuf.UInt32Value": {"uint32", true},
+	"google.protobuf.BoolValue":   {"bool", true},
+	"google.protobuf.FloatValue":  {"float32", true},
+	"google.protobuf.DoubleValue": {"float64", true},
+	"google.protobuf.BytesValue":  {"[]byte", true},
+}
+
+type protoToken struct {
+	kind byte // 'w' for words, 's' for strings, 'c' for comments, else the symbol
+	text string
+	line int
+}
+
+// tokenizeProto splits a .proto file into words (identifiers, dotted names
+// and numbers), strings, comments and symbols.
+func tokenizeProto(src string) ([]protoToken, error) {
+	var tokens []protoToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			tokens = append(tokens, protoToken{'c', strings.TrimSpace(src[i+2 : i+end]), line})
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			text := src[i+2 : i+2+end]
+			var lines []string
+			for _, l := range strings.Split(text, "\n") {
+				lines = append(lines, strings.TrimLeft(strings.TrimSpace(l), "* "))
+			}
+			tokens = append(tokens, protoToken{'c', strings.TrimSpace(strings.Join(lines, " ")), line})
+			line += strings.Count(text, "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, protoToken{'s', src[i+1 : end], line})
+			i = end + 1
+		case isProtoWord(rune(c)):
+			end := i
+			for end < len(src) && isProtoWord(rune(src[end])) {
+				end++
+			}
+			tokens = append(tokens, protoToken{'w', src[i:end], line})
+			i = end
+		default:
+			tokens = append(tokens, protoToken{c, string(c), line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isProtoWord(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || r == '+' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type protoParser struct {
+	tokens []protoToken
+	pos    int
+}
+
+// peek returns the next token that is not a comment, or a zero token at the
+// end of the file.
+func (p *protoParser) peek() protoToken {
+	for i := p.pos; i < len(p.tokens); i++ {
+		if p.tokens[i].kind != 'c' {
+			return p.tokens[i]
+		}
+	}
+	return protoToken{}
+}
+
+func (p *protoParser) next() protoToken {
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		p.pos++
+		if t.kind != 'c' {
+			return t
+		}
+	}
+	return protoToken{}
+}
+
+func (p *protoParser) expect(kind byte) (protoToken, error) {
+	t := p.next()
+	if t.kind != kind {
+		if t.kind == 0 {
+			return t, fmt.Errorf("unexpected end of file, expected %q", kind)
+		}
+		return t, fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+	}
+	return t, nil
+}
+
+// leading returns the comments right above the next token.
+func (p *protoParser) leading() string {
+	next := p.peek()
+	var lines []string
+	line := next.line
+	for i := p.pos; i < len(p.tokens) && p.tokens[i].kind == 'c'; i++ {
+		lines = append(lines, p.tokens[i].text)
+	}
+	// Only the comments ending on the line above belong to the token.
+	if n := len(lines); n > 0 && p.tokens[p.pos+n-1].line != line-1 {
+		return ""
+	}
+	return strings.Join(lines, " ")
+}
+
+// trailing returns the comment on the line of the last token read.
+func (p *protoParser) trailing() string {
+	if p.pos == 0 || p.pos >= len(p.tokens) {
+		return ""
+	}
+	if t := p.tokens[p.pos]; t.kind == 'c' && t.line == p.tokens[p.pos-1].line {
+		p.pos++
+		return t.text
+	}
+	return ""
+}
+
+// skip skips a statement up to its semicolon, or a block with its braces.
+func (p *protoParser) skip() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch t.kind {
+		case 0:
+			return fmt.Errorf("unexpected end of file")
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return nil
+			}
+		case ';':
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+type protoField struct {
+	label   string // optional, repeated, required, oneof or ""
+	typ     string // the type, or map<K,V>
+	name    string
+	number  int
+	comment string
+	ref     string // the model named by a "Ref: Model" comment
+}
+
+type protoMessage struct {
+	name   string
+	fields []protoField
+}
+
+type protoFile struct {
+	messages []*protoMessage
+	enums    map[string][]string
+	order    []string // enum names in declaration order
+}
+
+// parseProto parses the messages and enums of a .proto file. Nested
+// messages and enums are read as if they were declared at the top level;
+// services, options and extensions are skipped.
+func parseProto(src string) (*protoFile, error) {
+	tokens, err := tokenizeProto(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &protoParser{tokens: tokens}
+	f := &protoFile{enums: make(map[string][]string)}
+	for p.peek().kind != 0 {
+		if err := f.declaration(p); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *protoFile) declaration(p *protoParser) error {
+	t := p.peek()
+	switch {
+	case t.kind == ';':
+		p.next()
+		return nil
+	case t.kind == 'w' && t.text == "message":
+		p.next()
+		return f.message(p)
+	case t.kind == 'w' && t.text == "enum":
+		p.next()
+		return f.enum(p)
+	}
+	return p.skip()
+}
+
+func (f *protoFile) message(p *protoParser) error {
+	name, err := p.expect('w')
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect('{'); err != nil {
+		return err
+	}
+	m := &protoMessage{name: name.text}
+	f.messages = append(f.messages, m)
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == 0:
+			return fmt.Errorf("message %s: unexpected end of file", m.name)
+		case t.kind == '}':
+			p.next()
+			return nil
+		case t.kind == ';':
+			p.next()
+		case t.kind == 'w' && (t.text == "message" || t.text == "enum"):
+			if err := f.declaration(p); err != nil {
+				return err
+			}
+		case t.kind == 'w' && t.text == "oneof":
+			p.next()
+			if _, err := p.expect('w'); err != nil {
+				return err
+			}
+			if _, err := p.expect('{'); err != nil {
+				return err
+			}
+			for p.peek().kind == 'w' {
+				if p.peek().text == "option" {
+					if err := p.skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				field, err := protoFieldOf(p, "oneof")
+				if err != nil {
+					return fmt.Errorf("message %s: %w", m.name, err)
+				}
+				m.fields = append(m.fields, field)
+			}
+			if _, err := p.expect('}'); err != nil {
+				return err
+			}
+		case t.kind == 'w' && slices.Contains([]string{"option", "reserved", "extensions", "extend"}, t.text):
+			if err := p.skip(); err != nil {
+				return err
+			}
+		case t.kind == 'w':
+			field, err := protoFieldOf(p, "")
+			if err != nil {
+				return fmt.Errorf("message %s: %w", m.name, err)
+			}
+			m.fields = append(m.fields, field)
+		default:
+			if err := p.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protoFieldOf parses "[label] type name = number [options];".
+func protoFieldOf(p *protoParser, label string) (protoField, error) {
+	field := protoField{label: label, comment: p.leading()}
+	t := p.next()
+	if t.text == "optional" || t.text == "repeated" || t.text == "required" {
+		field.label = t.text
+		t = p.next()
+	}
+	field.typ = strings.TrimPrefix(t.text, ".")
+	if t.text == "map" {
+		var b strings.Builder
+		b.WriteString("map")
+		for {
+			t := p.next()
+			if t.kind == 0 {
+				return field, fmt.Errorf("unexpected end of file")
+			}
+			b.WriteString(t.text)
+			if t.kind == '>' {
+				break
+			}
+		}
+		field.typ = b.String()
+	}
+	name, err := p.expect('w')
+	if err != nil {
+		return field, err
+	}
+	field.name = name.text
+	if _, err := p.expect('='); err != nil {
+		return field, err
+	}
+	number, err := p.expect('w')
+	if err != nil {
+		return field, err
+	}
+	if field.number, err = strconv.Atoi(number.text); err != nil {
+		return field, fmt.Errorf("line %d: invalid field number %q", number.line, number.text)
+	}
+	if p.peek().kind == '[' {
+		for t := p.next(); t.kind != ']'; t = p.next() {
+			if t.kind == 0 {
+				return field, fmt.Errorf("unexpected end of file")
+			}
+		}
+	}
+	if _, err := p.expect(';'); err != nil {
+		return field, err
+	}
+	if comment := p.trailing(); comment != "" {
+		if ref, ok := strings.CutPrefix(comment, "Ref:"); ok {
+			field.ref = strings.TrimSpace(ref)
+		} else if field.comment == "" {
+			field.comment = comment
+		}
+	}
+	return field, nil
+}
+
+func (f *protoFile) enum(p *protoParser) error {
+	name, err := p.expect('w')
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect('{'); err != nil {
+		return err
+	}
+	var values []string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == 0:
+			return fmt.Errorf("enum %s: unexpected end of file", name.text)
+		case t.kind == '}':
+			f.enums[name.text] = values
+			f.order = append(f.order, name.text)
+			return nil
+		case t.kind == 'w' && (t.text == "option" || t.text == "reserved"):
+			p.pos--
+			if err := p.skip(); err != nil {
+				return err
+			}
+		case t.kind == 'w':
+			values = append(values, t.text)
+			p.pos--
+			if err := p.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protoEnumValues turns the values of a protobuf enum back into the values
+// of a model enum: the enum prefix and the UNSPECIFIED zero value that
+// protoEnumValue adds are dropped, e.g. ORDER_STATUS_PENDING -> PENDING.
+func protoEnumValues(enum string, values []string) []string {
+	prefix := protoEnumValue(enum, "")
+	var out []string
+	for _, v := range values {
+		if v == prefix+"UNSPECIFIED" || v == "UNSPECIFIED" {
+			continue
+		}
+		out = append(out, strings.TrimPrefix(v, prefix))
+	}
+	return out
+}
+
+// ImportProto builds models from the messages of the .proto file at path:
+//
+//   - scalar, repeated and map fields map back through the inverse of
+//     protobufType, google.protobuf.Timestamp to time.Time and
+//     google.protobuf.Duration to time.Duration
+//   - optional fields, oneof members and wrapper types such as
+//     google.protobuf.StringValue become nullable fields
+//   - enums become enums
+//   - a field of another imported message, or a NAME_id field commented
+//     "Ref: Model" as gokitgen writes them, becomes a relation
+//   - field numbers are kept, so the regenerated message stays wire
+//     compatible; relations written as messages get new numbers since
+//     they are regenerated as integers
+//
+// Request and response messages are skipped, as are id, created_at,
+// updated_at and deleted_at. Fields with no equivalent in the generator,
+// such as repeated messages and maps of messages, are skipped and listed in
+// skipped. Module and output path are left for the caller to fill in.
+func ImportProto(path string) (configs []*ModelConfig, skipped []string, err error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := parseProto(string(src))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	models := make(map[string]bool)
+	for _, m := range file.messages {
+		if !strings.HasSuffix(m.name, "Request") && !strings.HasSuffix(m.name, "Response") {
+			models[m.name] = true
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, m := range file.messages {
+		if !models[m.name] {
+			continue
+		}
+		config := &ModelConfig{ModelName: toPascal(m.name)}
+		local := make(map[string]string) // enums of the model by protobuf name
+		for _, pf := range m.fields {
+			if slices.Contains([]string{"id", "created_at", "updated_at", "deleted_at"}, pf.name) {
+				continue
+			}
+			field := Field{
+				Name:        toPascal(pf.name),
+				IsNullable:  pf.label == "optional" || pf.label == "oneof",
+				Comment:     pf.comment,
+				ProtoNumber: pf.number,
+			}
+			where := m.name + "." + pf.name
+			typ := pf.typ
+			if i := strings.LastIndex(typ, "."); i >= 0 && protoWellKnownTypes[typ].goType == "" && !strings.HasPrefix(typ, "map<") {
+				typ = typ[i+1:] // a message or enum of this package
+			}
+
+			switch wk, isWellKnown := protoWellKnownTypes[typ]; {
+			case strings.HasPrefix(typ, "map<"):
+				key, value, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(typ, "map<"), ">"), ",")
+				if key != "string" || value == "bytes" || protoScalarTypes[value] == "" {
+					skipped = append(skipped, fmt.Sprintf("%s: %s (only maps of strings to scalars are supported)", where, typ))
+					continue
+				}
+				field.Type = "map[string]" + protoScalarTypes[value]
+			case pf.label == "repeated" && (models[typ] || file.enums[typ] != nil || isWellKnown):
+				skipped = append(skipped, fmt.Sprintf("%s: repeated %s (has-many relation)", where, typ))
+				continue
+			case pf.ref != "" && models[pf.ref] && strings.HasSuffix(pf.name, "_id"):
+				field.Name = toPascal(strings.TrimSuffix(pf.name, "_id"))
+				field.Type, field.TypeIsRelation = toPascal(pf.ref), true
+			case models[typ]:
+				if typ == m.name {
+					skipped = append(skipped, fmt.Sprintf("%s: reference to its own message", where))
+					continue
+				}
+				// The relation is regenerated as a NAME_id integer, which
+				// must not reuse the number of a message field.
+				field.Type, field.TypeIsRelation = toPascal(typ), true
+				field.IsNullable, field.ProtoNumber = true, 0
+			case local[typ] != "":
+				field.Type, field.TypeIsEnum = local[typ], true
+			case file.enums[typ] != nil:
+				enum := Enum{Name: toPascal(typ), Values: protoEnumValues(toPascal(typ), file.enums[typ])}
+				if declared[enum.Name] {
+					enum.Name = config.ModelName + field.Name
+				}
+				if len(enum.Values) == 0 {
+					skipped = append(skipped, fmt.Sprintf("%s: enum %s has no values", where, typ))
+					continue
+				}
+				declared[enum.Name] = true
+				local[typ] = enum.Name
+				config.Enums = append(config.Enums, enum)
+				field.Type, field.TypeIsEnum = enum.Name, true
+			case isWellKnown:
+				field.Type = wk.goType
+				field.IsNullable = field.IsNullable || wk.nullable
+			case pf.label == "repeated" && typ == "bytes":
+				skipped = append(skipped, fmt.Sprintf("%s: repeated bytes", where))
+				continue
+			case protoScalarTypes[typ] != "":
+				field.Type = protoScalarTypes[typ]
+				if pf.label == "repeated" {
+					field.Type = "[]" + field.Type
+				}
+			default:
+				skipped = append(skipped, fmt.Sprintf("%s: unknown type %s", where, pf.typ))
+				continue
+			}
+			// Numbers taken by id, created_at and updated_at are given anew.
+			if field.ProtoNumber != 0 && field.ProtoNumber <= protoReserved {
+				skipped = append(skipped, fmt.Sprintf("%s: number %d belongs to %s in generated messages; imported with a new number, which is not wire compatible",
+					where, field.ProtoNumber, []string{"id", "created_at", "updated_at"}[field.ProtoNumber-1]))
+				field.ProtoNumber = 0
+			}
+			config.Fields = append(config.Fields, field)
+		}
+		if len(config.Fields) == 0 {
+			skipped = append(skipped, m.name+": no fields to import")
+			continue
+		}
+		configs = append(configs, config)
+	}
+	return configs, skipped, nil
+}
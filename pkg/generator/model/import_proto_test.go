@@ -0,0 +1,163 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProto(t *testing.T) {
+	f, err := parseProto(`
+syntax = "proto3";
+package shop.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/shop/api/proto/v1;pb";
+
+// Order is a purchase.
+message Order {
+  int64 id = 1;
+  optional string note = 4; // free text
+  repeated string tags = 5;
+  map<string, int64> counts = 6;
+  uint64 market_id = 7; // Ref: Market
+  oneof payment {
+    string card = 8;
+  }
+  message Line {
+    string sku = 1;
+  }
+  reserved 9, 10;
+}
+
+enum OrderStatus {
+  ORDER_STATUS_UNSPECIFIED = 0;
+  ORDER_STATUS_PENDING = 1 [deprecated = true];
+}
+
+service Orders {
+  rpc Get(Order) returns (Order);
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, m := range f.messages {
+		names = append(names, m.name)
+	}
+	if want := []string{"Order", "Line"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("messages = %q, want %q", names, want)
+	}
+	want := []protoField{
+		{name: "id", typ: "int64", number: 1},
+		{label: "optional", name: "note", typ: "string", number: 4, comment: "free text"},
+		{label: "repeated", name: "tags", typ: "string", number: 5},
+		{name: "counts", typ: "map<string,int64>", number: 6},
+		{name: "market_id", typ: "uint64", number: 7, ref: "Market"},
+		{label: "oneof", name: "card", typ: "string", number: 8},
+	}
+	for i, got := range f.messages[0].fields {
+		if i >= len(want) || !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("field %d = %+v", i, got)
+		}
+	}
+	if len(f.messages[0].fields) != len(want) {
+		t.Errorf("got %d fields, want %d", len(f.messages[0].fields), len(want))
+	}
+	if got, want := f.enums["OrderStatus"], []string{"ORDER_STATUS_UNSPECIFIED", "ORDER_STATUS_PENDING"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("enum values = %q, want %q", got, want)
+	}
+}
+
+func TestParseProtoErrors(t *testing.T) {
+	for _, src := range []string{
+		`message Order { string name = ; }`,
+		`message Order { string name = 1;`,
+		`message Order { string name = "1"; }`,
+	} {
+		if _, err := parseProto(src); err == nil {
+			t.Errorf("parseProto(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestProtoEnumValues(t *testing.T) {
+	tests := []struct {
+		enum   string
+		values []string
+		want   []string
+	}{
+		{"OrderStatus", []string{"ORDER_STATUS_UNSPECIFIED", "ORDER_STATUS_PENDING", "ORDER_STATUS_PAID"}, []string{"PENDING", "PAID"}},
+		{"OrderStatus", []string{"UNSPECIFIED", "Pending", "paid"}, []string{"Pending", "paid"}},
+	}
+	for _, tt := range tests {
+		if got := protoEnumValues(tt.enum, tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("protoEnumValues(%q, %q) = %q, want %q", tt.enum, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestImportProto(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.proto")
+	err := os.WriteFile(path, []byte(`
+syntax = "proto3";
+
+enum OrderStatus {
+  ORDER_STATUS_UNSPECIFIED = 0;
+  ORDER_STATUS_Pending = 1;
+}
+
+message Market {
+  int64 id = 1;
+  string name = 4;
+}
+
+message Order {
+  string title = 2;
+  OrderStatus status = 5;
+  optional int64 total = 6;
+  uint64 market_id = 7; // Ref: Market
+  repeated Market markets = 8;
+}
+
+message GetOrderRequest {
+  int64 id = 1;
+}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configs, skipped, err := ImportProto(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("got %d models, want 2", len(configs))
+	}
+	order := configs[1]
+	want := []Field{
+		{Name: "Title", Type: "string"},
+		{Name: "Status", Type: "OrderStatus", TypeIsEnum: true, ProtoNumber: 5},
+		{Name: "Total", Type: "int64", IsNullable: true, ProtoNumber: 6},
+		{Name: "Market", Type: "Market", TypeIsRelation: true, ProtoNumber: 7},
+	}
+	if !reflect.DeepEqual(order.Fields, want) {
+		t.Errorf("fields = %+v, want %+v", order.Fields, want)
+	}
+	if want := []Enum{{Name: "OrderStatus", Values: []string{"Pending"}}}; !reflect.DeepEqual(order.Enums, want) {
+		t.Errorf("enums = %+v, want %+v", order.Enums, want)
+	}
+
+	joined := strings.Join(skipped, "\n")
+	for _, s := range []string{"Order.title: number 2 belongs to created_at", "Order.markets: repeated Market"} {
+		if !strings.Contains(joined, s) {
+			t.Errorf("skipped = %q, want it to contain %q", skipped, s)
+		}
+	}
+}
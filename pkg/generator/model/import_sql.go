@@ -0,0 +1,722 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// sqlToken is a token of a DDL script.
+type sqlToken struct {
+	kind byte // 'w' word or number, 'i' quoted identifier, 's' string, or the symbol itself
+	text string
+}
+
+// tokenizeSQL splits script into tokens, dropping whitespace and comments.
+func tokenizeSQL(script string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	r := []rune(script)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-', c == '#':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			j := i + 2
+			for j+1 < len(r) && (r[j] != '*' || r[j+1] != '/') {
+				j++
+			}
+			if j+1 >= len(r) {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i = j + 2
+		// [name] quotes SQLite identifiers; text[] and ARRAY[...] are
+		// PostgreSQL arrays.
+		case c == '\'' || c == '"' || c == '`' || c == '[' && i+1 < len(r) && r[i+1] != ']' && !afterArray(tokens):
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(r); j++ {
+				if r[j] == closing {
+					// A doubled quote stands for the quote itself.
+					if j+1 < len(r) && r[j+1] == closing && c != '[' {
+						b.WriteRune(closing)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated %c", c)
+			}
+			kind := byte('i')
+			if c == '\'' {
+				kind = 's'
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: b.String()})
+			i = j + 1
+		case c == '_' || c == '$' || c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1]) || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (r[j] == '_' || r[j] == '$' || unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) ||
+				r[j] == '.' && unicode.IsDigit(r[i]) && j+1 < len(r) && unicode.IsDigit(r[j+1])) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: 'w', text: string(r[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{kind: byte(c), text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func afterArray(tokens []sqlToken) bool {
+	return len(tokens) > 0 && strings.EqualFold(tokens[len(tokens)-1].text, "ARRAY")
+}
+
+// sqlParser walks the tokens of one statement.
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *sqlParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *sqlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword consumes words if the next tokens are those keywords.
+func (p *sqlParser) keyword(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != 'w' || !strings.EqualFold(t.text, w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *sqlParser) symbol(c byte) bool {
+	if p.peek().kind == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// name reads a possibly qualified name such as public.orders and returns its
+// last part.
+func (p *sqlParser) name() string {
+	name := p.next().text
+	for p.peek().kind == '.' {
+		p.pos++
+		name = p.next().text
+	}
+	return name
+}
+
+// group reads a parenthesised group and returns the tokens inside it.
+func (p *sqlParser) group() []sqlToken {
+	if !p.symbol('(') {
+		return nil
+	}
+	start, depth := p.pos, 1
+	for !p.done() {
+		switch p.next().kind {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return p.tokens[start : p.pos-1]
+			}
+		}
+	}
+	return p.tokens[start:]
+}
+
+// splitSQL splits tokens at the sep symbols outside parentheses.
+func splitSQL(tokens []sqlToken, sep byte) [][]sqlToken {
+	var parts [][]sqlToken
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch t.kind {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
+
+// sqlText renders tokens back into SQL for default values.
+func sqlText(tokens []sqlToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.kind == 'w' && tokens[i-1].kind == 'w' {
+			b.WriteByte(' ')
+		}
+		switch t.kind {
+		case 's':
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		case 'i':
+			b.WriteString(`"` + t.text + `"`)
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+// sqlStrings returns the string literals among tokens.
+func sqlStrings(tokens []sqlToken) []string {
+	var values []string
+	for _, t := range tokens {
+		if t.kind == 's' {
+			values = append(values, t.text)
+		}
+	}
+	return values
+}
+
+// checkValues returns the values a CHECK constraint such as
+// status IN ('NEW', 'PAID') allows, or nil for other checks.
+func checkValues(check []sqlToken) []string {
+	for _, t := range check {
+		if t.kind == 'w' && (strings.EqualFold(t.text, "IN") || strings.EqualFold(t.text, "ANY")) {
+			return sqlStrings(check)
+		}
+	}
+	return nil
+}
+
+type sqlTable struct {
+	name    string
+	columns []*sqlColumn
+}
+
+type sqlColumn struct {
+	name       string
+	typ        string   // lower-cased type name, e.g. varchar or double precision
+	args       []string // type arguments, e.g. the size of varchar(32) or the values of enum(...)
+	unsigned   bool
+	array      bool
+	notNull    bool
+	primaryKey bool
+	unique     bool
+	def        string
+	references string
+	values     []string // allowed values of a CHECK (... IN (...)) constraint
+	comment    string
+	indexes    []string // gorm index settings
+}
+
+func (t *sqlTable) column(name string) *sqlColumn {
+	for _, c := range t.columns {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// sqlSchema is what a DDL script declares.
+type sqlSchema struct {
+	tables   []*sqlTable
+	types    map[string][]string // PostgreSQL enum types
+	dialect  string
+	warnings []string
+}
+
+func (s *sqlSchema) table(name string) *sqlTable {
+	for _, t := range s.tables {
+		if strings.EqualFold(t.name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// parseSQLSchema reads the CREATE TABLE, CREATE INDEX, CREATE TYPE ... AS
+// ENUM and ALTER TABLE ... ADD CONSTRAINT statements of script. Other
+// statements are ignored.
+func parseSQLSchema(script string) (*sqlSchema, error) {
+	tokens, err := tokenizeSQL(script)
+	if err != nil {
+		return nil, err
+	}
+	s := &sqlSchema{types: make(map[string][]string)}
+	if strings.Contains(script, "`") {
+		s.dialect = DatabaseMySQL
+	}
+	for _, t := range tokens {
+		switch {
+		case t.kind == 'w' && (strings.EqualFold(t.text, "AUTO_INCREMENT") || strings.EqualFold(t.text, "ENGINE")):
+			s.dialect = DatabaseMySQL
+		case t.kind == 'w' && (strings.EqualFold(t.text, "serial") || strings.EqualFold(t.text, "bigserial") ||
+			strings.EqualFold(t.text, "jsonb") || strings.EqualFold(t.text, "timestamptz")):
+			s.dialect = DatabasePostgres
+		case t.kind == 'w' && strings.EqualFold(t.text, "AUTOINCREMENT"):
+			s.dialect = DatabaseSQLite
+		}
+	}
+
+	for _, stmt := range splitSQL(tokens, ';') {
+		p := &sqlParser{tokens: stmt}
+		switch {
+		case p.keyword("CREATE"):
+			p.keyword("OR", "REPLACE")
+			_ = p.keyword("TEMPORARY") || p.keyword("TEMP") || p.keyword("UNLOGGED")
+			switch {
+			case p.keyword("TABLE"):
+				s.createTable(p)
+			case p.keyword("UNIQUE", "INDEX"):
+				s.createIndex(p, true)
+			case p.keyword("INDEX"):
+				s.createIndex(p, false)
+			case p.keyword("TYPE"):
+				name := p.name()
+				if p.keyword("AS", "ENUM") {
+					s.types[strings.ToLower(name)] = sqlStrings(p.group())
+					s.dialect = DatabasePostgres
+				}
+			}
+		case p.keyword("ALTER", "TABLE"):
+			p.keyword("ONLY")
+			p.keyword("IF", "EXISTS")
+			if t := s.table(p.name()); t != nil {
+				for _, action := range splitSQL(p.tokens[p.pos:], ',') {
+					ap := &sqlParser{tokens: action}
+					if ap.keyword("ADD") {
+						s.tableConstraint(t, ap)
+					}
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *sqlSchema) createTable(p *sqlParser) {
+	p.keyword("IF", "NOT", "EXISTS")
+	t := &sqlTable{name: p.name()}
+	for _, item := range splitSQL(p.group(), ',') {
+		ip := &sqlParser{tokens: item}
+		if !s.tableConstraint(t, ip) {
+			ip.pos = 0
+			t.columns = append(t.columns, parseSQLColumn(ip))
+		}
+	}
+	s.tables = append(s.tables, t)
+}
+
+// tableConstraint reads a table constraint such as FOREIGN KEY (...) into
+// the columns of t. It reports false if the tokens are a column instead.
+func (s *sqlSchema) tableConstraint(t *sqlTable, p *sqlParser) bool {
+	named := p.keyword("CONSTRAINT")
+	name := ""
+	if named {
+		name = p.name()
+	}
+	columns := func(tokens []sqlToken) []*sqlColumn {
+		var cols []*sqlColumn
+		for _, part := range splitSQL(tokens, ',') {
+			if len(part) > 0 {
+				if c := t.column(part[0].text); c != nil {
+					cols = append(cols, c)
+				}
+			}
+		}
+		return cols
+	}
+
+	switch {
+	case p.keyword("PRIMARY", "KEY"):
+		for _, c := range columns(p.group()) {
+			c.primaryKey = true
+		}
+	case p.keyword("FOREIGN", "KEY"):
+		if p.peek().kind != '(' {
+			p.next()
+		}
+		cols := columns(p.group())
+		if !p.keyword("REFERENCES") {
+			return true
+		}
+		ref := p.name()
+		if len(cols) != 1 {
+			s.warnings = append(s.warnings, fmt.Sprintf("%s: composite foreign key to %s", t.name, ref))
+			return true
+		}
+		cols[0].references = ref
+	case p.keyword("UNIQUE"):
+		_ = p.keyword("KEY") || p.keyword("INDEX")
+		if p.peek().kind != '(' {
+			name = p.name()
+		}
+		s.index(t, name, columns(p.group()), true)
+	case p.keyword("KEY"), p.keyword("INDEX"):
+		if p.peek().kind != '(' {
+			name = p.name()
+		}
+		s.index(t, name, columns(p.group()), false)
+	case p.keyword("CHECK"):
+		check := p.group()
+		values := checkValues(check)
+		for _, tok := range check {
+			if c := t.column(tok.text); c != nil && (tok.kind == 'w' || tok.kind == 'i') && len(values) > 0 {
+				c.values = values
+				break
+			}
+		}
+	case p.keyword("FULLTEXT"), p.keyword("SPATIAL"):
+		s.warnings = append(s.warnings, fmt.Sprintf("%s: %s index", t.name, strings.ToLower(p.tokens[p.pos-1].text)))
+	default:
+		return named
+	}
+	return true
+}
+
+func (s *sqlSchema) createIndex(p *sqlParser, unique bool) {
+	p.keyword("CONCURRENTLY")
+	p.keyword("IF", "NOT", "EXISTS")
+	name := p.name()
+	if !p.keyword("ON") {
+		return
+	}
+	p.keyword("ONLY")
+	t := s.table(p.name())
+	if t == nil {
+		return
+	}
+	if p.keyword("USING") {
+		p.next()
+	}
+	var cols []*sqlColumn
+	for _, part := range splitSQL(p.group(), ',') {
+		if len(part) > 0 {
+			if c := t.column(part[0].text); c != nil {
+				cols = append(cols, c)
+			}
+		}
+	}
+	s.index(t, name, cols, unique)
+}
+
+// index records a single-column index as a gorm index setting, omitting
+// the name when it is the one GORM would pick.
+func (s *sqlSchema) index(t *sqlTable, name string, cols []*sqlColumn, unique bool) {
+	if len(cols) != 1 {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s: composite index %s", t.name, name))
+		return
+	}
+	c := cols[0]
+	setting := "index"
+	if unique {
+		setting = "uniqueIndex"
+	}
+	if name != "" && name != "idx_"+t.name+"_"+c.name {
+		setting += ":" + name
+	}
+	c.indexes = append(c.indexes, setting)
+}
+
+func parseSQLColumn(p *sqlParser) *sqlColumn {
+	c := &sqlColumn{name: p.next().text}
+	c.typ = strings.ToLower(p.name())
+	switch {
+	case c.typ == "double" && p.keyword("PRECISION"):
+		c.typ = "double precision"
+	case c.typ == "character" && p.keyword("VARYING"):
+		c.typ = "varchar"
+	case c.typ == "timestamp" || c.typ == "time":
+		if p.peek().kind == '(' {
+			p.group()
+		}
+		if p.keyword("WITH", "TIME", "ZONE") {
+			c.typ += "tz"
+		}
+		p.keyword("WITHOUT", "TIME", "ZONE")
+	}
+	if p.peek().kind == '(' {
+		for _, arg := range splitSQL(p.group(), ',') {
+			if len(arg) > 0 {
+				c.args = append(c.args, arg[0].text)
+			}
+		}
+	}
+	if p.peek().kind == '[' {
+		p.next()
+		p.symbol(']')
+		c.array = true
+	}
+
+	for !p.done() {
+		switch {
+		case p.keyword("UNSIGNED"):
+			c.unsigned = true
+		case p.keyword("CONSTRAINT"):
+			p.next()
+		case p.keyword("NOT", "NULL"):
+			c.notNull = true
+		case p.keyword("PRIMARY", "KEY"):
+			c.primaryKey = true
+		case p.keyword("UNIQUE"):
+			p.keyword("KEY")
+			c.unique = true
+		case p.keyword("DEFAULT"):
+			c.def = parseSQLDefault(p)
+		case p.keyword("REFERENCES"):
+			c.references = p.name()
+			p.group()
+		case p.keyword("CHECK"):
+			c.values = checkValues(p.group())
+		case p.keyword("COMMENT"):
+			c.comment = p.next().text
+		case p.keyword("COLLATE"), p.keyword("CHARACTER", "SET"), p.keyword("CHARSET"), p.keyword("ON", "UPDATE"):
+			p.next()
+		case p.keyword("GENERATED"), p.keyword("AS"):
+			// Generated columns: the rest describes the expression.
+			p.pos = len(p.tokens)
+		default:
+			p.next()
+		}
+	}
+	return c
+}
+
+// parseSQLDefault reads the expression after DEFAULT, dropping PostgreSQL
+// casts such as 'NEW'::character varying.
+func parseSQLDefault(p *sqlParser) string {
+	start := p.pos
+	switch t := p.next(); {
+	case t.kind == '(':
+		p.pos--
+		p.group()
+	case t.kind == '-':
+		p.next()
+	case t.kind == 'w' && p.peek().kind == '(':
+		p.group()
+	}
+	def := sqlText(p.tokens[start:p.pos])
+	for p.peek().kind == ':' {
+		p.next()
+		p.symbol(':')
+		p.next()
+		p.keyword("VARYING")
+	}
+	if strings.EqualFold(def, "NULL") || strings.HasPrefix(strings.ToLower(def), "nextval") {
+		return ""
+	}
+	return def
+}
+
+// ImportSQL builds models from the CREATE TABLE statements of a MySQL,
+// PostgreSQL or SQLite schema:
+//
+//   - columns become fields, nullable unless NOT NULL
+//   - sizes, defaults, UNIQUE and single-column indexes become gorm tags
+//   - foreign keys to other tables of the script become relations
+//   - enum columns, PostgreSQL enum types and CHECK (... IN (...))
+//     constraints become enums
+//
+// The gorm.Model columns id, created_at, updated_at and deleted_at are
+// skipped. What has no equivalent, such as composite indexes, is listed in
+// skipped. The database of the models is set when the script shows its
+// dialect. Module and output path are left for the caller to fill in.
+func ImportSQL(script string) (configs []*ModelConfig, skipped []string, err error) {
+	s, err := parseSQLSchema(script)
+	if err != nil {
+		return nil, nil, err
+	}
+	configs, skipped = s.models()
+	return configs, skipped, nil
+}
+
+// models maps the tables of s to models.
+func (s *sqlSchema) models() ([]*ModelConfig, []string) {
+	skipped := s.warnings
+	models := make(map[string]string)
+	for _, t := range s.tables {
+		models[strings.ToLower(t.name)] = modelName(t.name)
+	}
+
+	var configs []*ModelConfig
+	declared := make(map[string]bool)
+	for _, t := range s.tables {
+		if strings.HasPrefix(t.name, "sqlite_") || t.name == "schema_migrations" || t.name == "goose_db_version" {
+			continue
+		}
+		config := &ModelConfig{ModelName: modelName(t.name), Database: s.dialect}
+		if tableName(config.ModelName) != t.name {
+			skipped = append(skipped, fmt.Sprintf("table name %s: the model %s uses %s", t.name, config.ModelName, tableName(config.ModelName)))
+		}
+		for _, c := range t.columns {
+			if slices.Contains([]string{"id", "created_at", "updated_at", "deleted_at"}, strings.ToLower(c.name)) {
+				continue
+			}
+
+			field := Field{Name: toPascal(c.name), IsNullable: !c.notNull && !c.primaryKey, Comment: c.comment}
+			var tag []string
+			ref, ok := models[strings.ToLower(c.references)]
+			switch {
+			case c.references != "" && ok && ref != config.ModelName:
+				field.Name = toPascal(strings.TrimSuffix(strings.ToLower(c.name), "_id"))
+				field.Type = ref
+				field.TypeIsRelation = true
+				if columnName(field) != c.name {
+					tag = append(tag, "column:"+c.name)
+				}
+				// The generated model indexes relations anyway.
+				c.indexes = slices.DeleteFunc(c.indexes, func(setting string) bool { return strings.HasPrefix(setting, "index") })
+			case c.references != "":
+				skipped = append(skipped, fmt.Sprintf("%s.%s: foreign key to %s, imported as a plain column", t.name, c.name, c.references))
+				fallthrough
+			default:
+				var values []string
+				field.Type, tag, values = sqlFieldType(s.dialect, c, s.types)
+				if field.Type == "" {
+					skipped = append(skipped, fmt.Sprintf("%s.%s: %s[] array", t.name, c.name, c.typ))
+					continue
+				}
+				if len(values) == 0 {
+					values = c.values
+				}
+				if len(values) > 0 {
+					enum := toPascal(strings.ToLower(c.typ))
+					if _, ok := s.types[strings.ToLower(c.typ)]; !ok || declared[enum] {
+						enum = config.ModelName + field.Name
+					}
+					declared[enum] = true
+					config.Enums = append(config.Enums, Enum{Name: enum, Values: values})
+					field.Type, field.TypeIsEnum, tag = enum, true, nil
+				}
+			}
+
+			if c.unique {
+				tag = append(tag, "unique")
+			}
+			tag = append(tag, c.indexes...)
+			if c.def != "" {
+				tag = append(tag, "default:"+c.def)
+			}
+			field.GormTag = strings.Join(tag, ";")
+			config.Fields = append(config.Fields, field)
+		}
+		configs = append(configs, config)
+	}
+	return configs, skipped
+}
+
+// modelName turns a table name into the name of its model, e.g. order_items
+// -> OrderItem.
+func modelName(table string) string {
+	name := strings.ToLower(table)
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		name = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "zes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		name = strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = strings.TrimSuffix(name, "s")
+	}
+	return toPascal(name)
+}
+
+// sqlFieldType returns the Go type of a column, the gorm settings keeping
+// its SQL type where the Go type alone would not, and the values of an enum
+// column. The Go type is empty for arrays, which have no field type.
+func sqlFieldType(dialect string, c *sqlColumn, enumTypes map[string][]string) (goType string, tag, values []string) {
+	if values, ok := enumTypes[strings.ToLower(c.typ)]; ok {
+		return "string", nil, values
+	}
+	size := ""
+	if len(c.args) > 0 {
+		size = c.args[0]
+	}
+	unsigned := func(signed string) string {
+		if c.unsigned {
+			return "u" + signed
+		}
+		return signed
+	}
+
+	switch {
+	case c.array:
+		return "", nil, nil
+	case c.typ == "enum":
+		return "string", nil, c.args
+	case c.typ == "varchar" || c.typ == "nvarchar" || c.typ == "char" || c.typ == "nchar":
+		if size != "" && size != "255" {
+			tag = append(tag, "size:"+size)
+		}
+		return "string", tag, nil
+	case strings.HasSuffix(c.typ, "text") || c.typ == "citext" || c.typ == "clob":
+		// Without a type, the migration would make the column varchar(255).
+		return "string", []string{"type:" + c.typ}, nil
+	case c.typ == "uuid":
+		return "uuid.UUID", nil, nil
+	case c.typ == "bool" || c.typ == "boolean" || c.typ == "tinyint" && size == "1":
+		return "bool", nil, nil
+	case c.typ == "tinyint":
+		return unsigned("int8"), nil, nil
+	case c.typ == "smallint" || c.typ == "int2" || c.typ == "smallserial":
+		return unsigned("int16"), nil, nil
+	case c.typ == "mediumint" || c.typ == "int4" || c.typ == "serial":
+		return unsigned("int32"), nil, nil
+	case c.typ == "int" || c.typ == "integer":
+		return unsigned("int"), nil, nil
+	case c.typ == "bigint" || c.typ == "int8" || c.typ == "bigserial":
+		return unsigned("int64"), nil, nil
+	case c.typ == "float" || c.typ == "real" && dialect != DatabaseSQLite || c.typ == "float4":
+		return "float32", nil, nil
+	case c.typ == "real" || c.typ == "double" || c.typ == "double precision" || c.typ == "float8":
+		return "float64", nil, nil
+	case c.typ == "decimal" || c.typ == "numeric":
+		if len(c.args) == 0 {
+			return "decimal.Decimal", []string{"type:" + c.typ}, nil
+		}
+		return "decimal.Decimal", []string{"type:" + c.typ + "(" + strings.Join(c.args, ",") + ")"}, nil
+	case c.typ == "date" || c.typ == "datetime" || strings.HasPrefix(c.typ, "timestamp"):
+		return "time.Time", nil, nil
+	case c.typ == "json" || c.typ == "jsonb":
+		return "json.RawMessage", []string{"type:" + c.typ}, nil
+	case strings.HasSuffix(c.typ, "blob") || c.typ == "bytea" || c.typ == "binary" || c.typ == "varbinary":
+		return "[]byte", nil, nil
+	}
+	return "string", []string{"type:" + c.typ}, nil
+}
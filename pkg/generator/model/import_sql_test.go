@@ -0,0 +1,125 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"words and symbols", "CREATE TABLE t (a int);", []string{"CREATE", "TABLE", "t", "(", "a", "int", ")", ";"}},
+		{"comments", "-- line\n# mysql\n/* block */ a", []string{"a"}},
+		{"quoted identifiers", "`a b` \"c\"\"d\" [e]", []string{"a b", `c"d`, "e"}},
+		{"strings", "'it''s'", []string{"it's"}},
+		{"numbers", "decimal(10,2) DEFAULT 1.5", []string{"decimal", "(", "10", ",", "2", ")", "DEFAULT", "1.5"}},
+		{"postgres arrays", "tags text[]", []string{"tags", "text", "[", "]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenizeSQL(tt.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tok := range tokens {
+				got = append(got, tok.text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeSQL(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeSQLErrors(t *testing.T) {
+	for _, script := range []string{"'open", "/* open", "`open"} {
+		if _, err := tokenizeSQL(script); err == nil {
+			t.Errorf("tokenizeSQL(%q) succeeded, want an error", script)
+		}
+	}
+}
+
+func TestImportSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    []Field
+		skipped string
+	}{
+		{
+			name:   "mysql columns",
+			script: "CREATE TABLE `orders` (`id` bigint unsigned AUTO_INCREMENT PRIMARY KEY, `title` varchar(100) NOT NULL, `amount` int unsigned DEFAULT 0, `note` text);",
+			want: []Field{
+				{Name: "Title", Type: "string", GormTag: "size:100"},
+				{Name: "Amount", Type: "uint", IsNullable: true, GormTag: "default:0"},
+				{Name: "Note", Type: "string", IsNullable: true, GormTag: "type:text"},
+			},
+		},
+		{
+			name:   "mysql enum",
+			script: "CREATE TABLE orders (status enum('PENDING','PAID') NOT NULL);",
+			want:   []Field{{Name: "Status", Type: "OrderStatus", TypeIsEnum: true}},
+		},
+		{
+			name:   "postgres types",
+			script: "CREATE TABLE posts (id bigserial PRIMARY KEY, slug citext NOT NULL, ref uuid NOT NULL, doc jsonb, price numeric(10,2) NOT NULL);",
+			want: []Field{
+				{Name: "Slug", Type: "string", GormTag: "type:citext"},
+				{Name: "Ref", Type: "uuid.UUID"},
+				{Name: "Doc", Type: "json.RawMessage", IsNullable: true, GormTag: "type:jsonb"},
+				{Name: "Price", Type: "decimal.Decimal", GormTag: "type:numeric(10,2)"},
+			},
+		},
+		{
+			name:    "postgres arrays are skipped",
+			script:  "CREATE TABLE posts (id serial PRIMARY KEY, title text NOT NULL, tags text[]);",
+			want:    []Field{{Name: "Title", Type: "string", GormTag: "type:text"}},
+			skipped: "posts.tags: text[] array",
+		},
+		{
+			name:    "foreign key outside the script",
+			script:  "CREATE TABLE orders (market_id bigint NOT NULL REFERENCES markets(id));",
+			want:    []Field{{Name: "MarketId", Type: "int64"}},
+			skipped: "orders.market_id: foreign key to markets, imported as a plain column",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, skipped, err := ImportSQL(tt.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(configs) != 1 {
+				t.Fatalf("got %d models, want 1", len(configs))
+			}
+			if got := configs[0].Fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %+v, want %+v", got, tt.want)
+			}
+			if tt.skipped != "" && !strings.Contains(strings.Join(skipped, "\n"), tt.skipped) {
+				t.Errorf("skipped = %q, want it to contain %q", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestImportSQLRelations(t *testing.T) {
+	configs, _, err := ImportSQL(`
+CREATE TABLE markets (id integer PRIMARY KEY, name text NOT NULL);
+CREATE TABLE orders (id integer PRIMARY KEY, market_id integer REFERENCES markets(id));
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("got %d models, want 2", len(configs))
+	}
+	want := []Field{{Name: "Market", Type: "Market", TypeIsRelation: true, IsNullable: true}}
+	if got := configs[1].Fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %+v, want %+v", got, want)
+	}
+}
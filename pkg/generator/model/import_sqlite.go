@@ -0,0 +1,311 @@
+package model
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"strings"
+)
+
+// ImportSQLite builds models from the tables of the SQLite database file at
+// path, like ImportSQL does from a script. The schema is read straight from
+// the sqlite_master table of the file, so no SQLite driver is needed. A
+// database in WAL mode is read together with the committed frames of its
+// path-wal file, which hold the changes not checkpointed yet.
+func ImportSQLite(path string) (configs []*ModelConfig, skipped []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	wal, err := os.ReadFile(path + "-wal")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	statements, err := sqliteSchema(data, wal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s, err := parseSQLSchema(strings.Join(statements, ";\n"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.dialect = DatabaseSQLite
+	configs, skipped = s.models()
+	return configs, skipped, nil
+}
+
+// sqliteSchema returns the CREATE TABLE statements of a SQLite database
+// file followed by its CREATE INDEX statements, in the order sqlite_master
+// lists them. wal is the write-ahead log of the database, if any.
+func sqliteSchema(data, wal []byte) ([]string, error) {
+	if len(data) < 100 || string(data[:16]) != "SQLite format 3\x00" {
+		return nil, errors.New("not a SQLite 3 database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	db := &sqliteFile{data: data, pageSize: pageSize}
+	// Read and write versions of 2 mean WAL mode.
+	if data[18] == 2 && len(wal) > 0 {
+		pages, err := sqliteWAL(wal, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w; checkpoint the database (PRAGMA wal_checkpoint) and try again", err)
+		}
+		db.wal = pages
+	}
+	// The header may have changed in the log too.
+	first, err := db.page(1)
+	if err != nil {
+		return nil, err
+	}
+	if encoding := binary.BigEndian.Uint32(first[56:]); encoding > 1 {
+		return nil, errors.New("only UTF-8 databases are supported")
+	}
+	db.usable = pageSize - int(first[20])
+
+	var tables, indexes []string
+	// sqlite_master is the table b-tree rooted at page 1.
+	err = db.walk(1, func(record []any) {
+		if len(record) < 5 {
+			return
+		}
+		kind, _ := record[0].(string)
+		sql, _ := record[4].(string)
+		switch {
+		case sql == "":
+		case kind == "table":
+			tables = append(tables, sql)
+		case kind == "index":
+			indexes = append(indexes, sql)
+		}
+	})
+	return append(tables, indexes...), err
+}
+
+// sqliteFile reads the pages of a SQLite database file, see
+// https://www.sqlite.org/fileformat.html.
+type sqliteFile struct {
+	data     []byte
+	pageSize int
+	usable   int
+	wal      map[int][]byte // pages newer in the write-ahead log
+}
+
+func (db *sqliteFile) page(n int) ([]byte, error) {
+	if page, ok := db.wal[n]; ok {
+		return page, nil
+	}
+	start := (n - 1) * db.pageSize
+	if n < 1 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("page %d is out of range", n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// walk calls fn with the record of every row of the table b-tree rooted at
+// page n.
+func (db *sqliteFile) walk(n int, fn func(record []any)) error {
+	page, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	header := page
+	if n == 1 {
+		header = page[100:]
+	}
+
+	cells := int(binary.BigEndian.Uint16(header[3:]))
+	switch header[0] {
+	case 0x05: // interior table page
+		for i := 0; i < cells; i++ {
+			offset := int(binary.BigEndian.Uint16(header[12+2*i:]))
+			if err := db.walk(int(binary.BigEndian.Uint32(page[offset:])), fn); err != nil {
+				return err
+			}
+		}
+		return db.walk(int(binary.BigEndian.Uint32(header[8:])), fn)
+	case 0x0d: // leaf table page
+		for i := 0; i < cells; i++ {
+			offset := int(binary.BigEndian.Uint16(header[8+2*i:]))
+			size, k := sqliteVarint(page[offset:])
+			_, m := sqliteVarint(page[offset+k:])
+			payload, err := db.payload(page[offset+k+m:], int(size))
+			if err != nil {
+				return err
+			}
+			record, err := sqliteRecord(payload)
+			if err != nil {
+				return err
+			}
+			fn(record)
+		}
+		return nil
+	}
+	return fmt.Errorf("page %d is not a table b-tree page", n)
+}
+
+// payload returns the size bytes of a cell payload starting at cell,
+// following the overflow pages of a payload that does not fit the page.
+func (db *sqliteFile) payload(cell []byte, size int) ([]byte, error) {
+	maxLocal := db.usable - 35
+	if size <= maxLocal {
+		return cell[:size], nil
+	}
+	minLocal := (db.usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(db.usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	payload := append([]byte(nil), cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local:]))
+	for len(payload) < size {
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := page[4:db.usable]
+		if rest := size - len(payload); len(chunk) > rest {
+			chunk = chunk[:rest]
+		}
+		payload = append(payload, chunk...)
+		next = int(binary.BigEndian.Uint32(page))
+	}
+	return payload, nil
+}
+
+// sqliteWAL returns the latest version of every page changed by the
+// committed transactions of a write-ahead log, see
+// https://www.sqlite.org/fileformat.html#the_write_ahead_log. The log ends
+// at the first frame that belongs to an older log or fails its checksum.
+func sqliteWAL(wal []byte, pageSize int) (map[int][]byte, error) {
+	if len(wal) < 32 {
+		return nil, nil
+	}
+	var order binary.ByteOrder
+	switch binary.BigEndian.Uint32(wal) {
+	case 0x377f0682:
+		order = binary.LittleEndian
+	case 0x377f0683:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("the write-ahead log is corrupt")
+	}
+	if int(binary.BigEndian.Uint32(wal[8:])) != pageSize {
+		return nil, errors.New("the write-ahead log does not match the database")
+	}
+	// checksum continues the running checksum s0, s1 over b.
+	checksum := func(s0, s1 uint32, b []byte) (uint32, uint32) {
+		for i := 0; i+8 <= len(b); i += 8 {
+			s0 += order.Uint32(b[i:]) + s1
+			s1 += order.Uint32(b[i+4:]) + s0
+		}
+		return s0, s1
+	}
+	s0, s1 := checksum(0, 0, wal[:24])
+	if s0 != binary.BigEndian.Uint32(wal[24:]) || s1 != binary.BigEndian.Uint32(wal[28:]) {
+		return nil, errors.New("the write-ahead log is corrupt")
+	}
+
+	pages := make(map[int][]byte)
+	pending := make(map[int][]byte)
+	for frame := wal[32:]; len(frame) >= 24+pageSize; frame = frame[24+pageSize:] {
+		if string(frame[8:16]) != string(wal[16:24]) {
+			break // left over from before the log was restarted
+		}
+		s0, s1 = checksum(s0, s1, frame[:8])
+		s0, s1 = checksum(s0, s1, frame[24:24+pageSize])
+		if s0 != binary.BigEndian.Uint32(frame[16:]) || s1 != binary.BigEndian.Uint32(frame[20:]) {
+			break
+		}
+		pending[int(binary.BigEndian.Uint32(frame))] = frame[24 : 24+pageSize]
+		// A frame with the database size ends a transaction.
+		if binary.BigEndian.Uint32(frame[4:]) != 0 {
+			for n, page := range pending {
+				pages[n] = page
+			}
+			clear(pending)
+		}
+	}
+	return pages, nil
+}
+
+// sqliteRecord decodes a record into nil, int64, float64, string and
+// []byte values.
+func sqliteRecord(payload []byte) ([]any, error) {
+	headerSize, n := sqliteVarint(payload)
+	if int(headerSize) > len(payload) {
+		return nil, errors.New("corrupt record")
+	}
+	var types []uint64
+	for pos := n; pos < int(headerSize); {
+		t, k := sqliteVarint(payload[pos:])
+		types = append(types, t)
+		pos += k
+	}
+
+	body := payload[headerSize:]
+	values := make([]any, len(types))
+	for i, t := range types {
+		var size int
+		switch {
+		case t == 0:
+			continue
+		case t == 8, t == 9:
+			values[i] = int64(t - 8)
+			continue
+		case t >= 1 && t <= 6:
+			size = []int{0, 1, 2, 3, 4, 6, 8}[t]
+		case t == 7:
+			size = 8
+		case t >= 12:
+			size = int(t-12) / 2
+		default:
+			return nil, errors.New("corrupt record")
+		}
+		if size > len(body) {
+			return nil, errors.New("corrupt record")
+		}
+		raw := body[:size]
+		body = body[size:]
+
+		switch {
+		case t >= 1 && t <= 6:
+			v := int64(0)
+			if size > 0 && raw[0]&0x80 != 0 {
+				v = -1
+			}
+			for _, b := range raw {
+				v = v<<8 | int64(b)
+			}
+			values[i] = v
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(raw))
+		case t >= 12 && t%2 == 0:
+			values[i] = append([]byte(nil), raw...)
+		case t >= 13:
+			values[i] = string(raw)
+		}
+	}
+	return values, nil
+}
+
+// sqliteVarint decodes a SQLite varint and returns it with its length.
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, len(b)
+}
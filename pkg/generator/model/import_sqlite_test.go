@@ -0,0 +1,95 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The databases in testdata/sqlite are built by testdata/sqlite/make.py.
+
+func TestImportSQLite(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		models []string
+		fields int // of the last model
+	}{
+		{
+			name:   "schema larger than a page",
+			file:   "pages.db",
+			models: []string{"Table00", "Table01", "Table38", "Table39"},
+			fields: 2,
+		},
+		{name: "overflow cells", file: "overflow.db", models: []string{"Wide"}, fields: 60},
+		{name: "wal mode", file: "wal.db", models: []string{"Market", "Order"}, fields: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, _, err := ImportSQLite(filepath.Join("testdata", "sqlite", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, c := range configs {
+				names = append(names, c.ModelName)
+			}
+			if len(names) > 4 {
+				names = append(names[:2], names[len(names)-2:]...)
+			}
+			if !reflect.DeepEqual(names, tt.models) {
+				t.Fatalf("models = %q, want %q", names, tt.models)
+			}
+			if got := len(configs[len(configs)-1].Fields); got != tt.fields {
+				t.Errorf("got %d fields, want %d", got, tt.fields)
+			}
+		})
+	}
+}
+
+func TestImportSQLiteWAL(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "sqlite", "wal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal, err := os.ReadFile(filepath.Join("testdata", "sqlite", "wal.db-wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without its log the database has no tables yet.
+	if statements, err := sqliteSchema(data, nil); err != nil || len(statements) != 0 {
+		t.Errorf("sqliteSchema without the log = %q, %v", statements, err)
+	}
+
+	// A torn write leaves the last transaction out.
+	torn := append([]byte(nil), wal...)
+	torn[len(torn)-1] ^= 0xff
+	statements, err := sqliteSchema(data, torn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || !strings.Contains(statements[0], "markets") {
+		t.Errorf("sqliteSchema with a torn log = %q, want only markets", statements)
+	}
+
+	corrupt := append([]byte(nil), wal...)
+	corrupt[0] = 0
+	if _, err := sqliteSchema(data, corrupt); err == nil || !strings.Contains(err.Error(), "checkpoint") {
+		t.Errorf("sqliteSchema with a corrupt log = %v, want a checkpoint hint", err)
+	}
+}
+
+func TestSQLiteSchemaErrors(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":        nil,
+		"not sqlite":   []byte(strings.Repeat("x", 200)),
+		"truncated db": []byte("SQLite format 3\x00\x04\x00" + strings.Repeat("\x00", 100)),
+	} {
+		if _, err := sqliteSchema(data, nil); err == nil {
+			t.Errorf("sqliteSchema(%s) succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestFile is where the manifest lives, relative to the output path.
+const ManifestFile = ".gokitgen/manifest.json"
+
+// Manifest records every file the generator wrote into an output path, so
+// later runs can tell generated content from hand-written changes, and the
+// table schema of every model the migrations were generated for.
+type Manifest struct {
+	Files   []ManifestEntry   `json:"files"`
+	Schemas map[string]Schema `json:"schemas,omitempty"`
+	// ProtoNumbers holds the protobuf field numbers each model's fields
+	// were given, by model and field name, see assignProtoNumbers.
+	ProtoNumbers map[string]map[string]int `json:"proto_numbers,omitempty"`
+}
+
+// ManifestEntry describes one generated file.
+type ManifestEntry struct {
+	Path        string `json:"path"` // slash separated, relative to the output path
+	Template    string `json:"template"`
+	Model       string `json:"model,omitempty"` // empty for shared files
+	SpecHash    string `json:"spec_hash"`
+	ContentHash string `json:"content_hash"`
+	// OutsideHash hashes the content without the bodies of its user
+	// regions, which regenerating keeps anyway.
+	OutsideHash string `json:"outside_hash,omitempty"`
+	Shared      bool   `json:"shared,omitempty"`
+}
+
+// LoadManifest reads the manifest of outputPath from out. A missing
+// manifest is not an error; an empty one is returned instead.
+func LoadManifest(out Output, outputPath string) (*Manifest, error) {
+	data, err := out.ReadFile(filepath.Join(outputPath, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	return &m, nil
+}
+
+// save writes the manifest unless it is unchanged, so dry runs and repeated
+// runs do not report it as a written file.
+func (m *Manifest) save(out Output, outputPath string) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	name := filepath.Join(outputPath, ManifestFile)
+	if existing, err := out.ReadFile(name); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	return out.WriteFile(name, data)
+}
+
+// Lookup returns the entry recorded for the file at path, which includes
+// the output path like GeneratedFile.Path.
+func (m *Manifest) Lookup(outputPath, path string) (ManifestEntry, bool) {
+	rel := manifestRel(outputPath, path)
+	for _, e := range m.Files {
+		if e.Path == rel {
+			return e, true
+		}
+	}
+	return ManifestEntry{}, false
+}
+
+func (m *Manifest) record(outputPath string, file GeneratedFile) {
+	entry := ManifestEntry{
+		Path:        manifestRel(outputPath, file.Path),
+		Template:    file.Template,
+		Model:       file.Model,
+		SpecHash:    file.SpecHash,
+		ContentHash: hashContent(file.Content),
+		OutsideHash: hashContent(stripRegions(file.Content)),
+		Shared:      file.Shared,
+	}
+	m.remove(entry.Path)
+	m.Files = append(m.Files, entry)
+}
+
+func (m *Manifest) remove(rel string) {
+	for i, e := range m.Files {
+		if e.Path == rel {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+func manifestRel(outputPath, path string) string {
+	rel, err := filepath.Rel(outputPath, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hashSpec hashes the template data a file was rendered from.
+func hashSpec(data any) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(encoded), nil
+}
+
+// FileState tells how a generated file compares to what was generated.
+type FileState int
+
+const (
+	FileUnchanged FileState = iota
+	FileModified
+	FileMissing
+)
+
+func (s FileState) String() string {
+	switch s {
+	case FileUnchanged:
+		return "unchanged"
+	case FileModified:
+		return "modified"
+	case FileMissing:
+		return "missing"
+	}
+	return fmt.Sprintf("FileState(%d)", int(s))
+}
+
+// FileStatus is a manifest entry together with the current state of the file.
+type FileStatus struct {
+	ManifestEntry
+	State FileState
+}
+
+// Status compares every file of the manifest in outputPath with its
+// current content.
+func Status(out Output, outputPath string) ([]FileStatus, error) {
+	m, err := LoadManifest(out, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]FileStatus, 0, len(m.Files))
+	for _, e := range m.Files {
+		state, err := fileState(out, outputPath, e)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, FileStatus{ManifestEntry: e, State: state})
+	}
+	return statuses, nil
+}
+
+func fileState(out Output, outputPath string, e ManifestEntry) (FileState, error) {
+	data, err := out.ReadFile(filepath.Join(outputPath, filepath.FromSlash(e.Path)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileMissing, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if hashContent(data) != e.ContentHash {
+		return FileModified, nil
+	}
+	return FileUnchanged, nil
+}
+
+// Clean removes the generated files of modelName that were not modified
+// since they were generated and drops them from the manifest. Modified
+// files are left alone and returned in kept. Shared files are never
+// removed since other models are registered in them too; the model is
+// taken out of them instead and they are returned in updated. Migrations
+// are kept as well, with the schema recorded for them: the table they
+// created is still in the databases they ran on.
+func Clean(out Output, outputPath, modelName string) (removed, updated []string, kept []FileStatus, err error) {
+	m, err := LoadManifest(out, outputPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	found := false
+	for _, e := range append([]ManifestEntry(nil), m.Files...) {
+		if e.Shared || e.Model != modelName || isMigrationTemplate(e.Template) {
+			continue
+		}
+		found = true
+
+		state, err := fileState(out, outputPath, e)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch state {
+		case FileModified:
+			kept = append(kept, FileStatus{ManifestEntry: e, State: state})
+			continue
+		case FileUnchanged:
+			if err := out.Remove(filepath.Join(outputPath, filepath.FromSlash(e.Path))); err != nil {
+				return nil, nil, nil, err
+			}
+			removed = append(removed, e.Path)
+		}
+		m.remove(e.Path)
+	}
+	if !found {
+		return nil, nil, nil, fmt.Errorf("no generated files recorded for model %s", modelName)
+	}
+	for i, e := range m.Files {
+		if !e.Shared {
+			continue
+		}
+		path := filepath.Join(outputPath, filepath.FromSlash(e.Path))
+		data, err := out.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		cleaned, err := removeShared(data, modelName)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %w", e.Path, err)
+		}
+		if bytes.Equal(cleaned, data) {
+			continue
+		}
+		if err := out.WriteFile(path, cleaned); err != nil {
+			return nil, nil, nil, err
+		}
+		// A file that was unchanged stays unchanged; edits by hand are
+		// still reported as such.
+		if hashContent(data) == e.ContentHash {
+			m.Files[i].ContentHash = hashContent(cleaned)
+			m.Files[i].OutsideHash = hashContent(stripRegions(cleaned))
+		}
+		updated = append(updated, e.Path)
+	}
+
+	return removed, updated, kept, m.save(out, outputPath)
+}
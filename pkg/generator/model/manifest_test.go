@@ -0,0 +1,94 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testProject(fields ...Field) []*ModelConfig {
+	return []*ModelConfig{
+		{ModelName: "Order", ModulePath: "example.com/shop", OutputPath: "svc", GenerateHTTP: true, Fields: []Field{{Name: "Title", Type: "string"}}},
+		{ModelName: "Product", ModulePath: "example.com/shop", OutputPath: "svc", GenerateHTTP: true, Fields: append([]Field{{Name: "Name", Type: "string"}}, fields...)},
+	}
+}
+
+func TestClean(t *testing.T) {
+	out := NewMemoryOutput()
+	if err := NewGenerator(out).Generate(testProject()...); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, updated, kept, err := Clean(out, "svc", "Product")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) == 0 || len(kept) != 0 {
+		t.Errorf("removed %d files and kept %v", len(removed), kept)
+	}
+	for _, path := range removed {
+		if strings.HasPrefix(path, "migrations/") {
+			t.Errorf("clean removed the migration %s", path)
+		}
+	}
+	if len(updated) != 1 || updated[0] != "internal/api/transports/http/routes.go" {
+		t.Errorf("updated = %q, want routes.go", updated)
+	}
+	routes := string(out.Files[filepath.Join("svc", "internal", "api", "transports", "http", "routes.go")])
+	if strings.Contains(routes, "Product") || !strings.Contains(routes, "Order") {
+		t.Errorf("routes.go after clean:\n%s", routes)
+	}
+
+	// The table is still there, so generating the model again alters it
+	// instead of creating it a second time.
+	if err := NewGenerator(out).Generate(testProject(Field{Name: "Price", Type: "int64"})...); err != nil {
+		t.Fatal(err)
+	}
+	var creates, alters int
+	for _, name := range out.Names() {
+		switch {
+		case strings.Contains(name, "_create_products.up.sql"):
+			creates++
+		case strings.Contains(name, "_alter_products.up.sql"):
+			alters++
+		}
+	}
+	if creates != 1 || alters != 1 {
+		t.Errorf("got %d create and %d alter migrations of products, want 1 and 1", creates, alters)
+	}
+}
+
+func TestRegionEditsAreNotConflicts(t *testing.T) {
+	out := NewMemoryOutput()
+	configs := testProject()
+	if err := NewGenerator(out).Generate(configs...); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("svc", "internal", "service", "order_service.go")
+	src := string(out.Files[path])
+	i := strings.Index(src, "// gokitgen:begin delete\n")
+	if i < 0 {
+		t.Fatal("no delete region in order_service.go")
+	}
+	i += len("// gokitgen:begin delete\n")
+	edited := src[:i] + "\t// custom code\n" + src[i:]
+	out.Files[path] = []byte(edited)
+
+	configs[0].Fields = append(configs[0].Fields, Field{Name: "Note", Type: "string"})
+	_, conflicts, err := NewGenerator(out).Plan(configs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+
+	// An edit outside the regions still is one.
+	out.Files[path] = []byte(edited + "\n// more\n")
+	if _, conflicts, err = NewGenerator(out).Plan(configs...); err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 {
+		t.Errorf("got %d conflicts, want 1", len(conflicts))
+	}
+}
@@ -0,0 +1,173 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// migrationTemplates are rendered into the golang-migrate migration that
+// creates the table of a model.
+var migrationTemplates = []string{"migration_up.sql.tmpl", "migration_down.sql.tmpl"}
+
+// isMigrationTemplate reports whether the files of the template called name
+// are migrations.
+func isMigrationTemplate(name string) bool {
+	return strings.HasPrefix(name, "migration_")
+}
+
+// alterData is the template data of a migration altering the table of a
+// model.
+type alterData struct {
+	*ModelConfig
+	Statements []string
+	Warnings   []string
+}
+
+// migrationTime is the clock migration versions are taken from.
+var migrationTime = time.Now
+
+// generateMigrations renders the migrations creating the tables of configs.
+// Each model gets its own version, one second apart, and models come after
+// the models they reference so the foreign keys can be created.
+func generateMigrations(r *renderer, configs []*ModelConfig) error {
+	base := migrationTime().UTC()
+	for i, config := range migrationOrder(configs) {
+		version := base.Add(time.Duration(i) * time.Second).Format("20060102150405")
+		name := fmt.Sprintf("%s_create_%s", version, tableName(config.ModelName))
+		dir := filepath.Join(config.OutputPath, "migrations")
+		if err := r.render("migration_up.sql.tmpl", filepath.Join(dir, name+".up.sql"), config); err != nil {
+			return err
+		}
+		if err := r.render("migration_down.sql.tmpl", filepath.Join(dir, name+".down.sql"), config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrationOrder sorts configs so that a model comes after the models of
+// the same run it references. Relations to other models keep their order.
+func migrationOrder(configs []*ModelConfig) []*ModelConfig {
+	byName := make(map[string]*ModelConfig)
+	for _, c := range configs {
+		byName[c.ModelName] = c
+	}
+
+	var ordered []*ModelConfig
+	visiting := make(map[string]bool)
+	var visit func(c *ModelConfig)
+	visit = func(c *ModelConfig) {
+		if visiting[c.ModelName] || slices.Contains(ordered, c) {
+			return
+		}
+		visiting[c.ModelName] = true
+		for _, f := range c.Fields {
+			if ref, ok := byName[f.Type]; ok && f.TypeIsRelation {
+				visit(ref)
+			}
+		}
+		ordered = append(ordered, c)
+	}
+	for _, c := range configs {
+		visit(c)
+	}
+	return ordered
+}
+
+// planMigrations fits the migrations among files to the schemas recorded in
+// manifest. A model whose table was created by an earlier run keeps its
+// create migration, and a change of its schema gets a migration altering
+// the table instead. The schemas of configs are recorded in manifest.
+func planMigrations(files []GeneratedFile, configs []*ModelConfig, manifest *Manifest) ([]GeneratedFile, error) {
+	outputPath := configs[0].OutputPath
+	if manifest.Schemas == nil {
+		manifest.Schemas = make(map[string]Schema)
+	}
+
+	// Alter migrations come after the create migrations of the same run.
+	base := migrationTime().UTC().Add(time.Duration(len(configs)) * time.Second)
+	r := &renderer{}
+	created := make(map[string]bool)
+	for _, config := range configs {
+		schema := schemaOf(config)
+		old, ok := manifest.Schemas[config.ModelName]
+		manifest.Schemas[config.ModelName] = schema
+		if !ok || old.Database != schema.Database {
+			continue
+		}
+		created[config.ModelName] = true
+
+		up, warnings := schemaChanges(old, schema)
+		if len(up) == 0 && len(warnings) == 0 {
+			continue
+		}
+		down, downWarnings := schemaChanges(schema, old)
+
+		version := base.Add(time.Duration(len(r.files)/2) * time.Second).Format("20060102150405")
+		name := filepath.Join(outputPath, "migrations", fmt.Sprintf("%s_alter_%s", version, schema.Table))
+		if err := r.render("migration_alter_up.sql.tmpl", name+".up.sql", alterData{config, up, warnings}); err != nil {
+			return nil, err
+		}
+		r.files[len(r.files)-1].warnings = warnings
+		if err := r.render("migration_alter_down.sql.tmpl", name+".down.sql", alterData{config, down, downWarnings}); err != nil {
+			return nil, err
+		}
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if !created[file.Model] || !slices.Contains(migrationTemplates, file.Template) {
+			kept = append(kept, file)
+		}
+	}
+	keepMigrationPaths(kept, manifest, outputPath)
+	return append(kept, r.files...), nil
+}
+
+// keepMigrationPaths renames freshly rendered create migrations to the files
+// the manifest recorded for the same model, so a run does not add a second
+// migration creating a table that already has one.
+func keepMigrationPaths(files []GeneratedFile, manifest *Manifest, outputPath string) {
+	for i := range files {
+		file := &files[i]
+		if !slices.Contains(migrationTemplates, file.Template) {
+			continue
+		}
+		for _, e := range manifest.Files {
+			if e.Template == file.Template && e.Model == file.Model {
+				file.Path = filepath.Join(outputPath, filepath.FromSlash(e.Path))
+				break
+			}
+		}
+	}
+}
+
+// createColumns returns the column and constraint definitions of the
+// CREATE TABLE statement of c: the columns of its schema followed by the
+// check and foreign key constraints.
+func createColumns(c *ModelConfig) []string {
+	schema := schemaOf(c)
+	var defs, constraints []string
+	for _, col := range schema.Columns {
+		defs = append(defs, columnSQL(schema.Database, col))
+		for _, constraint := range []string{checkSQL(schema.Database, schema.Table, col), foreignKeySQL(schema.Database, schema.Table, col)} {
+			if constraint != "" {
+				constraints = append(constraints, constraint)
+			}
+		}
+	}
+	return append(defs, constraints...)
+}
+
+// createIndexes returns the CREATE INDEX statements of the table of c.
+func createIndexes(c *ModelConfig) []string {
+	schema := schemaOf(c)
+	statements := make([]string, len(schema.Indexes))
+	for i, idx := range schema.Indexes {
+		statements[i] = indexSQL(schema.Database, schema.Table, idx)
+	}
+	return statements
+}
@@ -1,9 +1,9 @@
 package model
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
@@ -12,402 +12,348 @@ import (
 //go:embed templates/*
 var tmplFS embed.FS
 
-func GenerateCode(config *ModelConfig) error {
-	dirs := []string{
-		// filepath.Join(config.OutputPath, "internal", "type"),
-		filepath.Join(config.OutputPath, "internal", "service"),
-		filepath.Join(config.OutputPath, "internal", "service" , "dto"),
-		filepath.Join(config.OutputPath, "internal", "api", "endpoints"),
-		filepath.Join(config.OutputPath, "internal", "api", "transports"),
-		filepath.Join(config.OutputPath, "internal", "api", "transports" , "http"),
-		filepath.Join(config.OutputPath, "internal", "api", "transports" , "grpc"),
-	}
-
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return err
-		}
-	}
-
-	if err := generateModel(config); err != nil {
-		return err
-	}
+// aggregateData is the template data for files shared by every model of a
+// run, such as routes.go.
+type aggregateData struct {
+	ModulePath string
+	Database   string
+	Models     []*ModelConfig
+}
 
-	if err := generateProto(config); err != nil {
-		return err
-	}
+// GeneratedFile is a single file rendered by the generator.
+type GeneratedFile struct {
+	Path     string // location on disk, including the output path
+	Template string // template the file was rendered from
+	Content  []byte
+	// Model is the model the file belongs to, empty for shared files.
+	Model string
+	// SpecHash identifies the template data the file was rendered from.
+	SpecHash string
+	// Shared files register every model (routes.go, register.go). An
+	// existing one is extended with the models it is missing rather than
+	// overwritten.
+	Shared bool
+
+	// mergeErr is set when an existing shared file could not be extended;
+	// Content then holds the existing file.
+	mergeErr error
+	// warnings are printed when the file is written, e.g. about a
+	// migration that drops data.
+	warnings []string
+}
 
-	// if err := generateType(config); err != nil {
-	// 	return err
-	// }
+// renderer collects the files of one generator run in memory.
+type renderer struct {
+	files []GeneratedFile
+}
 
-	if err := generateRepository(config); err != nil {
+func (r *renderer) render(name, path string, data any) error {
+	content, err := renderTemplate(name, data)
+	if err != nil {
 		return err
 	}
-
-	if err := generateService(config); err != nil {
-		return err
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil
 	}
 
-	if err := generateEndpoint(config); err != nil {
-		return err
+	file := GeneratedFile{Path: path, Template: name}
+	modulePath := ""
+	switch data := data.(type) {
+	case *ModelConfig:
+		file.Model, modulePath = data.ModelName, data.ModulePath
+	case alterData:
+		file.Model, modulePath = data.ModelName, data.ModulePath
+	case aggregateData:
+		modulePath = data.ModulePath
 	}
 
-	if config.GenerateHTTP {
-		if err := generateTransportHTTP(config); err != nil {
-			return err
-		}
-
-		if err := generateTransportHTTPTest(config); err != nil {
-			return err
-		}
-	}
-	if config.GenerategRPC {
-		if err := generateTransportgRPC(config); err != nil {
-			return err
-		}
-
-		if err := generateTransportGRPCTest(config); err != nil {
-			return err
+	if strings.HasSuffix(path, ".go") {
+		formatted, err := formatGo(content, modulePath)
+		if err != nil {
+			return syntaxError(name, path, content, err)
 		}
+		content = formatted
 	}
+	file.Content = content
 
-	if err := generateRoutes(config); err != nil {
+	if file.SpecHash, err = hashSpec(data); err != nil {
 		return err
 	}
-
-	if config.GenerateTests {
-		if err := generateServiceTest(config); err != nil {
-			return err
-		}
-		if err := generateAPITest(config); err != nil {
-			return err
-		}
-	}
-
-	fmt.Printf("✅ Code generated successfully in %s\n", config.OutputPath)
+	r.files = append(r.files, file)
 	return nil
 }
 
-// func generateType(config *ModelConfig) error {
-// 	root, err := GetProjectRoot()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	tmplPath := filepath.Join(root, "pkg", "generator", "model", "templates", "type.go.tmpl")
-
-// 	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(TemplateFuncMap()).ParseFiles(tmplPath)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	f, err := os.Create(filepath.Join(config.OutputPath, "pkg", "type", strings.ToLower(config.ModelName)+".go"))
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer f.Close()
-
-// 	return tmpl.Execute(f, config)
-// }
-
-func generateService(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/service.go.tmpl")
+func renderTemplate(name string, data any) ([]byte, error) {
+	tmplContent, err := tmplFS.ReadFile("templates/" + name)
 	if err != nil {
-		return fmt.Errorf("failed to read embedded template service.go.tmpl: %w", err)
+		return nil, fmt.Errorf("failed to read embedded template %s: %w", name, err)
 	}
 
-	tmpl, err := template.New("service.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
+	tmpl, err := template.New(name).Funcs(TemplateFuncMap()).Parse(string(tmplContent))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
 	}
 
-	f, err := os.Create(filepath.Join(config.OutputPath, "internal", "service", strings.ToLower(config.ModelName)+"_service.go"))
-	if err != nil {
-		return err
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
 	}
-	defer f.Close()
+	return buf.Bytes(), nil
+}
 
-	err = tmpl.Execute(f, config) 
-	if err != nil {
-		return err
+// RenderFiles renders everything GenerateCode would write, without touching
+// the disk.
+func RenderFiles(configs ...*ModelConfig) ([]GeneratedFile, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no model to generate")
 	}
-
-
-	tmplDtoContent, err := tmplFS.ReadFile("templates/dto.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template dto.go.tmpl: %w", err)
+	for _, config := range configs[1:] {
+		if config.OutputPath != configs[0].OutputPath || config.ModulePath != configs[0].ModulePath || config.Dialect() != configs[0].Dialect() {
+			return nil, fmt.Errorf("model %s: all models must share the module, output path and database", config.ModelName)
+		}
 	}
 
-	tmplDto, err := template.New("dto.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplDtoContent))
-	if err != nil {
-		return err
+	r := &renderer{}
+	for _, config := range configs {
+		if err := generateModelLayers(r, config); err != nil {
+			if len(configs) > 1 {
+				return nil, fmt.Errorf("model %s: %w", config.ModelName, err)
+			}
+			return nil, err
+		}
 	}
 
-	fDto, err := os.Create(filepath.Join(config.OutputPath, "internal", "service" , "dto" , strings.ToLower(config.ModelName)+"_dto.go"))
-	if err != nil {
-		return err
+	if err := generateMigrations(r, configs); err != nil {
+		return nil, err
 	}
-	defer fDto.Close()
 
-	err = tmplDto.Execute(fDto, config)
-	if err != nil {
-		return err
+	data := aggregateData{ModulePath: configs[0].ModulePath, Database: configs[0].Dialect()}
+	if err := generateServiceErrors(r, configs[0].OutputPath, data); err != nil {
+		return nil, err
 	}
-
-	return nil
-}
-
-func generateEndpoint(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/endpoint.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template endpoint.go.tmpl: %w", err)
+	if err := generateCommonRepository(r, configs[0].OutputPath, data); err != nil {
+		return nil, err
 	}
-
-	tmpl, err := template.New("endpoint.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
+	if err := generateDatabase(r, configs[0].OutputPath, data); err != nil {
+		return nil, err
 	}
 
-	f, err := os.Create(filepath.Join(config.OutputPath, "internal", "api", "endpoints", strings.ToLower(config.ModelName)+"_endpoint.go"))
-	if err != nil {
-		return err
+	for _, config := range configs {
+		if config.GenerateHTTP {
+			data.Models = append(data.Models, config)
+		}
 	}
-	defer f.Close()
-
-	return tmpl.Execute(f, config)
-}
-
-func generateTransportHTTP(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/transport_http.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template transport_http.go.tmpl: %w", err)
+	if err := generateRoutes(r, configs[0].OutputPath, data); err != nil {
+		return nil, err
 	}
 
-	tmpl, err := template.New("transport_http.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
+	data.Models = nil
+	for _, config := range configs {
+		if config.GenerategRPC {
+			data.Models = append(data.Models, config)
+		}
 	}
-
-	f, err := os.Create(filepath.Join(config.OutputPath, "internal", "api", "transports" , "http", strings.ToLower(config.ModelName)+"_http.go"))
-	if err != nil {
-		return err
+	if err := generateGRPCRegistration(r, configs[0].OutputPath, data); err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
-	return tmpl.Execute(f, config)
+	return r.files, nil
 }
 
-func generateTransportgRPC(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/transport_grpc.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template transport_grpc.go.tmpl: %w", err)
+func generateModelLayers(r *renderer, config *ModelConfig) error {
+	if err := generateModel(r, config); err != nil {
+		return err
 	}
 
-	tmpl, err := template.New("transport_grpc.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("failed to parse gRPC transport template: %w", err)
+	if err := generateProto(r, config); err != nil {
+		return err
 	}
 
-	transportsDir := filepath.Join(config.OutputPath, "internal", "api", "transports" , "grpc")
-	os.MkdirAll(transportsDir, 0755)
-
-	f, err := os.Create(filepath.Join(transportsDir, strings.ToLower(config.ModelName)+"_grpc.go"))
-	if err != nil {
+	if err := generateRepository(r, config); err != nil {
 		return err
 	}
-	defer f.Close()
-
-	return tmpl.Execute(f, config)
-}
 
-func generateProto(config *ModelConfig) error {
-	if !config.GenerateHTTP && !config.GenerategRPC {
-		return nil
+	if err := generateService(r, config); err != nil {
+		return err
 	}
 
-	tmplContent, err := tmplFS.ReadFile("templates/proto.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template proto.go.tmpl: %w", err)
+	if err := generateMapper(r, config); err != nil {
+		return err
 	}
 
-	tmpl, err := template.New("proto.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("failed to parse proto template: %w", err)
+	if err := generateEndpoint(r, config); err != nil {
+		return err
 	}
 
-	protoDir := filepath.Join(config.OutputPath, "api", "proto", "v1")
-	os.MkdirAll(protoDir, 0755)
+	if config.GenerateHTTP {
+		if err := generateTransportHTTP(r, config); err != nil {
+			return err
+		}
 
-	f, err := os.Create(filepath.Join(protoDir, strings.ToLower(config.ModelName)+".proto"))
-	if err != nil {
-		return err
+		if err := generateTransportHTTPTest(r, config); err != nil {
+			return err
+		}
 	}
-	defer f.Close()
-
-	return tmpl.Execute(f, config)
-}
+	if config.GenerategRPC {
+		if err := generateTransportgRPC(r, config); err != nil {
+			return err
+		}
 
-func generateRoutes(config *ModelConfig) error {
-	path := filepath.Join(config.OutputPath, "internal", "api" , "transports" , "http", "routes.go")
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		tmplContent, err := tmplFS.ReadFile("templates/routes.go.tmpl")
-		if err != nil {
-			return fmt.Errorf("failed to read embedded template routes.go.tmpl: %w", err)
+		if err := generateTransportGRPCTest(r, config); err != nil {
+			return err
 		}
+	}
 
-		tmpl, err := template.New("routes.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-		if err != nil {
+	if config.GenerateTests {
+		if err := generateServiceTest(r, config); err != nil {
 			return err
 		}
-		f, err := os.Create(path)
-		if err != nil {
+		if err := generateAPITest(r, config); err != nil {
+			return err
+		}
+		if err := generateMapperTest(r, config); err != nil {
+			return err
+		}
+		if err := generateRepositoryTest(r, config); err != nil {
 			return err
 		}
-		defer f.Close()
-		return tmpl.Execute(f, config)
-	} else {
-		fmt.Println("⚠️  routes.go already exists — manual update required for now.")
-		return nil
 	}
-}
 
-func generateServiceTest(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/service_test.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template service_test.go.tmpl: %w", err)
-	}
+	return nil
+}
 
-	tmpl, err := template.New("service_test.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
-	}
+func generateModel(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "models", strings.ToLower(config.ModelName)+".go")
+	return r.render("model.go.tmpl", path, config)
+}
 
-	f, err := os.Create(filepath.Join(config.OutputPath, "internal", "service", strings.ToLower(config.ModelName)+"_service_test.go"))
-	if err != nil {
-		return err
+func generateProto(r *renderer, config *ModelConfig) error {
+	if !config.GenerateHTTP && !config.GenerategRPC {
+		return nil
 	}
-	defer f.Close()
 
-	return tmpl.Execute(f, config)
+	path := filepath.Join(config.OutputPath, "api", "proto", "v1", strings.ToLower(config.ModelName)+".proto")
+	return r.render("proto.go.tmpl", path, config)
 }
 
-func generateAPITest(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/api_test.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template api_test.go.tmpl: %w", err)
-	}
+func generateRepository(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "repositories", strings.ToLower(config.ModelName)+"_repository.go")
+	return r.render("repository.go.tmpl", path, config)
+}
 
-	tmpl, err := template.New("api_test.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
-	}
+func generateRepositoryTest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "repositories", strings.ToLower(config.ModelName)+"_repository_test.go")
+	return r.render("repository_test.go.tmpl", path, config)
+}
 
-	f, err := os.Create(filepath.Join(config.OutputPath, "internal", "api", "endpoints", strings.ToLower(config.ModelName)+"_endpoint_test.go"))
-	if err != nil {
+func generateService(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "service", strings.ToLower(config.ModelName)+"_service.go")
+	if err := r.render("service.go.tmpl", path, config); err != nil {
 		return err
 	}
-	defer f.Close()
 
-	return tmpl.Execute(f, config)
+	path = filepath.Join(config.OutputPath, "internal", "service", "dto", strings.ToLower(config.ModelName)+"_dto.go")
+	return r.render("dto.go.tmpl", path, config)
 }
 
-func generateModel(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/model.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template model.go.tmpl: %w", err)
-	}
+func generateMapper(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "mappers", strings.ToLower(config.ModelName)+"_mapper.go")
+	return r.render("mapper.go.tmpl", path, config)
+}
 
-	tmpl, err := template.New("model.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
-	}
+func generateEndpoint(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "endpoints", strings.ToLower(config.ModelName)+"_endpoint.go")
+	return r.render("endpoint.go.tmpl", path, config)
+}
 
-	modelsDir := filepath.Join(config.OutputPath, "internal", "models")
-	os.MkdirAll(modelsDir, 0755)
+func generateTransportHTTP(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "transports", "http", strings.ToLower(config.ModelName)+"_http.go")
+	return r.render("transport_http.go.tmpl", path, config)
+}
 
-	f, err := os.Create(filepath.Join(modelsDir, strings.ToLower(config.ModelName)+".go"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+func generateTransportHTTPTest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "transports", "http", strings.ToLower(config.ModelName)+"_http_test.go")
+	return r.render("transport_http_test.go.tmpl", path, config)
+}
 
-	return tmpl.Execute(f, config)
+func generateTransportgRPC(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "transports", "grpc", strings.ToLower(config.ModelName)+"_grpc.go")
+	return r.render("transport_grpc.go.tmpl", path, config)
 }
 
-func generateRepository(config *ModelConfig) error {
-	tmplContent, err := tmplFS.ReadFile("templates/repository.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template repository.go.tmpl: %w", err)
-	}
+func generateTransportGRPCTest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "transports", "grpc", strings.ToLower(config.ModelName)+"_grpc_test.go")
+	return r.render("transport_grpc_test.go.tmpl", path, config)
+}
 
-	tmpl, err := template.New("repository.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
-	}
+func generateServiceTest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "service", strings.ToLower(config.ModelName)+"_service_test.go")
+	return r.render("service_test.go.tmpl", path, config)
+}
 
-	repoDir := filepath.Join(config.OutputPath, "internal", "repositories")
-	os.MkdirAll(repoDir, 0755)
+func generateAPITest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "api", "endpoints", strings.ToLower(config.ModelName)+"_endpoint_test.go")
+	return r.render("api_test.go.tmpl", path, config)
+}
 
-	f, err := os.Create(filepath.Join(repoDir, strings.ToLower(config.ModelName)+"_repository.go"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+func generateMapperTest(r *renderer, config *ModelConfig) error {
+	path := filepath.Join(config.OutputPath, "internal", "mappers", strings.ToLower(config.ModelName)+"_mapper_test.go")
+	return r.render("mapper_test.go.tmpl", path, config)
+}
 
-	return tmpl.Execute(f, config)
+// generateServiceErrors renders the errors shared by every service. The file
+// does not depend on the models, so each run renders the same content.
+func generateServiceErrors(r *renderer, outputPath string, data aggregateData) error {
+	path := filepath.Join(outputPath, "internal", "service", "errors.go")
+	return r.render("service_errors.go.tmpl", path, data)
 }
 
-func generateTransportGRPCTest(config *ModelConfig) error {
-	if !config.GenerategRPC {
-		return nil
-	}
+// generateCommonRepository renders CommonBehaviorRepository, which every
+// model repository embeds.
+func generateCommonRepository(r *renderer, outputPath string, data aggregateData) error {
+	path := filepath.Join(outputPath, "internal", "repositories", "common.go")
+	return r.render("repository_common.go.tmpl", path, data)
+}
 
-	tmplContent, err := tmplFS.ReadFile("templates/transport_grpc_test.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template transport_grpc_test.go.tmpl: %w", err)
-	}
+// generateDatabase renders the code that opens the connection for the
+// selected dialect.
+func generateDatabase(r *renderer, outputPath string, data aggregateData) error {
+	path := filepath.Join(outputPath, "internal", "database", "database.go")
+	return r.render("database.go.tmpl", path, data)
+}
 
-	tmpl, err := template.New("transport_grpc_test.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
-		return err
+func generateRoutes(r *renderer, outputPath string, data aggregateData) error {
+	if len(data.Models) == 0 {
+		return nil
 	}
 
-	transportsDir := filepath.Join(config.OutputPath, "internal", "api", "transports" , "grpc")
-	os.MkdirAll(transportsDir, 0755)
-
-	f, err := os.Create(filepath.Join(transportsDir, strings.ToLower(config.ModelName)+"_grpc_test.go"))
-	if err != nil {
+	path := filepath.Join(outputPath, "internal", "api", "transports", "http", "errors.go")
+	if err := r.render("errors_http.go.tmpl", path, data); err != nil {
 		return err
 	}
-	defer f.Close()
 
-	return tmpl.Execute(f, config)
+	path = filepath.Join(outputPath, "internal", "api", "transports", "http", "routes.go")
+	return r.renderShared("routes.go.tmpl", path, data)
 }
 
-func generateTransportHTTPTest(config *ModelConfig) error {
-	if !config.GenerateHTTP {
+func generateGRPCRegistration(r *renderer, outputPath string, data aggregateData) error {
+	if len(data.Models) == 0 {
 		return nil
 	}
 
-	tmplContent, err := tmplFS.ReadFile("templates/transport_http_test.go.tmpl")
-	if err != nil {
-		return fmt.Errorf("failed to read embedded template transport_http_test.go.tmpl: %w", err)
-	}
-
-	tmpl, err := template.New("transport_http_test.go.tmpl").Funcs(TemplateFuncMap()).Parse(string(tmplContent))
-	if err != nil {
+	path := filepath.Join(outputPath, "internal", "api", "transports", "grpc", "errors.go")
+	if err := r.render("errors_grpc.go.tmpl", path, data); err != nil {
 		return err
 	}
 
-	transportsDir := filepath.Join(config.OutputPath, "internal", "api", "transports", "http")
-	os.MkdirAll(transportsDir, 0755)
+	path = filepath.Join(outputPath, "internal", "api", "transports", "grpc", "register.go")
+	return r.renderShared("register_grpc.go.tmpl", path, data)
+}
 
-	f, err := os.Create(filepath.Join(transportsDir, strings.ToLower(config.ModelName)+"_http_test.go"))
-	if err != nil {
+func (r *renderer) renderShared(name, path string, data any) error {
+	if err := r.render(name, path, data); err != nil {
 		return err
 	}
-	defer f.Close()
-
-	return tmpl.Execute(f, config)
-}
\ No newline at end of file
+	r.files[len(r.files)-1].Shared = true
+	return nil
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Output is where the generator writes its files. Names are the paths
+// produced by RenderFiles, i.e. they already include the output path.
+type Output interface {
+	// ReadFile returns the current content of name. When there is none the
+	// error satisfies errors.Is(err, fs.ErrNotExist).
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	// Remove deletes name; it is used to clean up generated files.
+	Remove(name string) error
+}
+
+// DiskOutput writes files to the local filesystem, creating directories as
+// needed.
+type DiskOutput struct{}
+
+func (DiskOutput) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (DiskOutput) WriteFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+func (DiskOutput) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// MemoryOutput keeps every written file in a map, which is handy for tests
+// and tools that post-process the generated code.
+type MemoryOutput struct {
+	Files map[string][]byte
+}
+
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{Files: make(map[string][]byte)}
+}
+
+func (m *MemoryOutput) ReadFile(name string) ([]byte, error) {
+	data, ok := m.Files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemoryOutput) WriteFile(name string, data []byte) error {
+	if m.Files == nil {
+		m.Files = make(map[string][]byte)
+	}
+	m.Files[filepath.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemoryOutput) Remove(name string) error {
+	if _, ok := m.Files[filepath.Clean(name)]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.Files, filepath.Clean(name))
+	return nil
+}
+
+// Names returns the written file names in sorted order.
+func (m *MemoryOutput) Names() []string {
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DryRunOutput prints what would be written instead of writing it. Reads
+// still go to disk so existing files are reported correctly.
+type DryRunOutput struct {
+	W     io.Writer
+	Files int
+	Bytes int
+}
+
+func (d *DryRunOutput) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (d *DryRunOutput) WriteFile(name string, data []byte) error {
+	d.Files++
+	d.Bytes += len(data)
+	_, err := fmt.Fprintf(d.W, "  %-64s %7d bytes\n", name, len(data))
+	return err
+}
+
+func (d *DryRunOutput) Remove(name string) error {
+	_, err := fmt.Fprintf(d.W, "  %-64s removed\n", name)
+	return err
+}
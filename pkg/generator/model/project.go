@@ -0,0 +1,183 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project groups several models that are generated together into the same
+// service. Module, output, transport and database settings are shared by
+// every model.
+type Project struct {
+	ModulePath    string         `yaml:"module" json:"module"`
+	OutputPath    string         `yaml:"output,omitempty" json:"output,omitempty"`
+	GenerateHTTP  bool           `yaml:"http,omitempty" json:"http,omitempty"`
+	GenerategRPC  bool           `yaml:"grpc,omitempty" json:"grpc,omitempty"`
+	GenerateTests bool           `yaml:"tests,omitempty" json:"tests,omitempty"`
+	DBManager     string         `yaml:"db_manager,omitempty" json:"db_manager,omitempty"`
+	Database      string         `yaml:"database,omitempty" json:"database,omitempty"`
+	Models        []*ModelConfig `yaml:"models" json:"models"`
+}
+
+// LoadProject reads a project spec listing several models under "models"
+// and validates it. A single-model spec (see LoadSpec) is accepted too and
+// becomes a project with one model.
+func LoadProject(path string) (*Project, error) {
+	project, err := ReadProject(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := project.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return project, nil
+}
+
+// ReadProject reads a project spec like LoadProject but leaves validation to
+// the caller, which may complete the models first, e.g. from flags.
+func ReadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasModelsKey(path, data) {
+		config, err := ReadSpec(path)
+		if err != nil {
+			return nil, err
+		}
+		return &Project{
+			ModulePath:    config.ModulePath,
+			OutputPath:    config.OutputPath,
+			GenerateHTTP:  config.GenerateHTTP,
+			GenerategRPC:  config.GenerategRPC,
+			GenerateTests: config.GenerateTests,
+			DBManager:     config.DBManager,
+			Database:      config.Database,
+			Models:        []*ModelConfig{config},
+		}, nil
+	}
+
+	project := &Project{}
+	if err := decodeSpec(path, data, project); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+
+	project.normalize()
+	return project, nil
+}
+
+// SaveProject writes project as a spec file that LoadProject can read back.
+func SaveProject(path string, project *Project) error {
+	return writeSpec(path, project)
+}
+
+func hasModelsKey(path string, data []byte) bool {
+	var probe map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if yaml.NewDecoder(bytes.NewReader(data)).Decode(&probe) != nil {
+			return false
+		}
+	case ".json":
+		if json.Unmarshal(data, &probe) != nil {
+			return false
+		}
+	}
+	_, ok := probe["models"]
+	return ok
+}
+
+// normalize pushes the project-wide settings down into every model so each
+// ModelConfig can be handed to the generator on its own.
+func (p *Project) normalize() {
+	if p.OutputPath == "" {
+		p.OutputPath = "./"
+	}
+
+	for _, m := range p.Models {
+		if m == nil {
+			continue
+		}
+		if m.ModulePath == "" {
+			m.ModulePath = p.ModulePath
+		}
+		if m.OutputPath == "" {
+			m.OutputPath = p.OutputPath
+		}
+		if m.DBManager == "" {
+			m.DBManager = p.DBManager
+		}
+		if m.Database == "" {
+			m.Database = p.Database
+		}
+		m.GenerateHTTP = m.GenerateHTTP || p.GenerateHTTP
+		m.GenerategRPC = m.GenerategRPC || p.GenerategRPC
+		m.GenerateTests = m.GenerateTests || p.GenerateTests
+		m.normalize()
+	}
+}
+
+// Validate checks every model and resolves "Ref:" relations between them: a
+// relation must point at another model declared in the same project.
+func (p *Project) Validate() error {
+	if err := p.ValidateModels(); err != nil {
+		return err
+	}
+
+	models := make(map[string]bool)
+	for _, m := range p.Models {
+		models[m.ModelName] = true
+	}
+	for _, m := range p.Models {
+		for _, f := range m.Fields {
+			if f.TypeIsRelation && !models[f.Type] {
+				return fmt.Errorf("model %s: field %s references unknown model %s", m.ModelName, f.Name, f.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateModels is Validate without the check of relations, for models
+// that may refer to models generated by earlier runs, such as imported
+// ones.
+func (p *Project) ValidateModels() error {
+	if len(p.Models) == 0 {
+		return fmt.Errorf("at least one model is required")
+	}
+
+	models := make(map[string]*ModelConfig)
+	enums := make(map[string]string)
+	for i, m := range p.Models {
+		if m == nil {
+			return fmt.Errorf("model #%d is empty", i+1)
+		}
+		if err := m.Validate(); err != nil {
+			if m.ModelName == "" {
+				return fmt.Errorf("model #%d: %w", i+1, err)
+			}
+			return fmt.Errorf("model %s: %w", m.ModelName, err)
+		}
+		if _, ok := models[m.ModelName]; ok {
+			return fmt.Errorf("model %s is declared more than once", m.ModelName)
+		}
+		if m.ModulePath != p.Models[0].ModulePath || m.OutputPath != p.Models[0].OutputPath {
+			return fmt.Errorf("model %s: all models must share the module and output path", m.ModelName)
+		}
+		for _, e := range m.Enums {
+			if owner, ok := enums[e.Name]; ok {
+				return fmt.Errorf("enum %s is declared by both %s and %s", e.Name, owner, m.ModelName)
+			}
+			enums[e.Name] = m.ModelName
+		}
+		models[m.ModelName] = m
+	}
+	return nil
+}
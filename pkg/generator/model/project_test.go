@@ -0,0 +1,56 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectValidate(t *testing.T) {
+	order := &ModelConfig{ModelName: "Order", ModulePath: "example.com/shop", OutputPath: "svc",
+		Fields: []Field{{Name: "Market", Type: "Market", TypeIsRelation: true}}}
+	market := &ModelConfig{ModelName: "Market", ModulePath: "example.com/shop", OutputPath: "svc",
+		Fields: []Field{{Name: "Name", Type: "string"}}}
+
+	if err := (&Project{Models: []*ModelConfig{order, market}}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	alone := &Project{Models: []*ModelConfig{order}}
+	if err := alone.Validate(); err == nil || !strings.Contains(err.Error(), "unknown model Market") {
+		t.Errorf("Validate of a relation to a missing model = %v", err)
+	}
+	// Imported models may refer to models generated before.
+	if err := alone.ValidateModels(); err != nil {
+		t.Errorf("ValidateModels: %v", err)
+	}
+	if err := (&Project{Models: []*ModelConfig{order, order}}).ValidateModels(); err == nil {
+		t.Error("ValidateModels accepted a model declared twice")
+	}
+}
+
+func TestReadProject(t *testing.T) {
+	dir := t.TempDir()
+	specs := map[string]string{
+		"order.yaml": "name: Order\nfields:\n  - name: Title\n    type: string\n",
+		"shop.yaml":  "models:\n  - name: Order\n    fields:\n      - name: Title\n        type: string\n",
+	}
+	for name, spec := range specs {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// The module may still come from a flag, so only LoadProject
+		// insists on it.
+		project, err := ReadProject(path)
+		if err != nil {
+			t.Fatalf("ReadProject(%s): %v", name, err)
+		}
+		if len(project.Models) != 1 || project.Models[0].ModelName != "Order" {
+			t.Errorf("ReadProject(%s) = %+v", name, project.Models)
+		}
+		if _, err := LoadProject(path); err == nil || !strings.Contains(err.Error(), "module path is required") {
+			t.Errorf("LoadProject(%s) = %v, want a missing module error", name, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// User regions are blocks of a generated file that belong to the user:
+//
+//	// gokitgen:begin create
+//	... hand-written code ...
+//	// gokitgen:end
+//
+// When a file is generated again, the body of every region found in the
+// existing file replaces the default body of the region with the same name
+// in the fresh output, so business logic survives regeneration.
+const (
+	regionBegin = "// gokitgen:begin "
+	regionEnd   = "// gokitgen:end"
+
+	orphanedPrefix = "orphaned-"
+)
+
+// region is one user region of a file. Body holds the lines between the
+// markers, each with its line ending.
+type region struct {
+	Name string
+	Body string
+}
+
+// parseRegions returns the user regions of src in the order they appear.
+func parseRegions(src []byte) ([]region, error) {
+	var (
+		regions []region
+		current *region
+		seen    = make(map[string]bool)
+	)
+	for i, line := range strings.SplitAfter(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, regionBegin):
+			name := strings.TrimSpace(strings.TrimPrefix(trimmed, regionBegin))
+			if current != nil {
+				return nil, fmt.Errorf("line %d: region %q starts inside region %q", i+1, name, current.Name)
+			}
+			if name == "" {
+				return nil, fmt.Errorf("line %d: region without a name", i+1)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("line %d: duplicate region %q", i+1, name)
+			}
+			seen[name] = true
+			current = &region{Name: name}
+		case trimmed == regionEnd:
+			if current == nil {
+				return nil, fmt.Errorf("line %d: %s without a matching begin", i+1, regionEnd)
+			}
+			regions = append(regions, *current)
+			current = nil
+		case current != nil:
+			current.Body += line
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("region %q is never closed", current.Name)
+	}
+	return regions, nil
+}
+
+// stripRegions returns src with the bodies of its user regions removed,
+// which is what is left of a file the user only edited inside regions.
+func stripRegions(src []byte) []byte {
+	var (
+		out    strings.Builder
+		inside bool
+	)
+	for _, line := range strings.SplitAfter(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, regionBegin):
+			inside = true
+		case trimmed == regionEnd:
+			inside = false
+		case inside:
+			continue
+		}
+		out.WriteString(line)
+	}
+	return []byte(out.String())
+}
+
+// mergeRegions copies the user regions of existing into rendered. Regions
+// the templates no longer have are kept at the end of the file, commented
+// out, so nothing the user wrote is lost.
+func mergeRegions(rendered, existing []byte) ([]byte, error) {
+	old, err := parseRegions(existing)
+	if err != nil {
+		return nil, err
+	}
+	if len(old) == 0 {
+		return rendered, nil
+	}
+	bodies := make(map[string]string, len(old))
+	for _, r := range old {
+		bodies[r.Name] = r.Body
+	}
+
+	var (
+		out  strings.Builder
+		skip bool
+		used = make(map[string]bool)
+	)
+	for _, line := range strings.SplitAfter(string(rendered), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, regionBegin):
+			out.WriteString(line)
+			name := strings.TrimSpace(strings.TrimPrefix(trimmed, regionBegin))
+			if body, ok := bodies[name]; ok {
+				out.WriteString(body)
+				used[name] = true
+				skip = true
+			}
+		case trimmed == regionEnd:
+			out.WriteString(line)
+			skip = false
+		case !skip:
+			out.WriteString(line)
+		}
+	}
+
+	for _, r := range old {
+		if used[r.Name] || strings.TrimSpace(r.Body) == "" {
+			continue
+		}
+		if !strings.HasSuffix(out.String(), "\n") {
+			out.WriteString("\n")
+		}
+		// Regions orphaned by an earlier run are already commented out.
+		name := strings.TrimPrefix(r.Name, orphanedPrefix)
+		fmt.Fprintf(&out, "\n// Region %q no longer exists in the template; its code is kept below.\n", name)
+		out.WriteString(regionBegin + orphanedPrefix + name + "\n")
+		if strings.HasPrefix(r.Name, orphanedPrefix) {
+			out.WriteString(r.Body)
+		} else {
+			for _, line := range strings.SplitAfter(r.Body, "\n") {
+				if line != "" {
+					out.WriteString("// " + line)
+				}
+			}
+		}
+		if !strings.HasSuffix(out.String(), "\n") {
+			out.WriteString("\n")
+		}
+		out.WriteString(regionEnd + "\n")
+	}
+	return []byte(out.String()), nil
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []region
+		wantErr bool
+	}{
+		{
+			name: "regions",
+			src:  "a\n\t// gokitgen:begin one\n\tx := 1\n\t// gokitgen:end\n// gokitgen:begin two\n// gokitgen:end\n",
+			want: []region{{Name: "one", Body: "\tx := 1\n"}, {Name: "two"}},
+		},
+		{name: "none", src: "package a\n"},
+		{name: "nested", src: "// gokitgen:begin a\n// gokitgen:begin b\n", wantErr: true},
+		{name: "unnamed", src: "// gokitgen:begin \n// gokitgen:end\n", wantErr: true},
+		{name: "duplicate", src: "// gokitgen:begin a\n// gokitgen:end\n// gokitgen:begin a\n// gokitgen:end\n", wantErr: true},
+		{name: "end without begin", src: "// gokitgen:end\n", wantErr: true},
+		{name: "never closed", src: "// gokitgen:begin a\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRegions([]byte(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRegions error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRegions = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rendered string
+		existing string
+		want     string
+	}{
+		{
+			name:     "body kept",
+			rendered: "func F() {\n\t// gokitgen:begin f\n\treturn\n\t// gokitgen:end\n}\n",
+			existing: "func F() {\n\t// gokitgen:begin f\n\tcustom()\n\t// gokitgen:end\n}\n",
+			want:     "func F() {\n\t// gokitgen:begin f\n\tcustom()\n\t// gokitgen:end\n}\n",
+		},
+		{
+			name:     "no regions in the existing file",
+			rendered: "// gokitgen:begin f\nnew\n// gokitgen:end\n",
+			existing: "old\n",
+			want:     "// gokitgen:begin f\nnew\n// gokitgen:end\n",
+		},
+		{
+			name:     "orphaned region commented out",
+			rendered: "x\n",
+			existing: "// gokitgen:begin gone\ncustom()\n// gokitgen:end\n",
+			want:     "x\n\n// Region \"gone\" no longer exists in the template; its code is kept below.\n// gokitgen:begin orphaned-gone\n// custom()\n// gokitgen:end\n",
+		},
+		{
+			name:     "orphaned region kept as is",
+			rendered: "x\n",
+			existing: "// gokitgen:begin orphaned-gone\n// custom()\n// gokitgen:end\n",
+			want:     "x\n\n// Region \"gone\" no longer exists in the template; its code is kept below.\n// gokitgen:begin orphaned-gone\n// custom()\n// gokitgen:end\n",
+		},
+		{
+			name:     "empty orphaned region dropped",
+			rendered: "x\n",
+			existing: "// gokitgen:begin gone\n// gokitgen:end\n",
+			want:     "x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeRegions([]byte(tt.rendered), []byte(tt.existing))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeRegions =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripRegions(t *testing.T) {
+	src := "a\n// gokitgen:begin f\ncustom()\n// gokitgen:end\nb\n"
+	want := "a\n// gokitgen:begin f\n// gokitgen:end\nb\n"
+	if got := string(stripRegions([]byte(src))); got != want {
+		t.Errorf("stripRegions = %q, want %q", got, want)
+	}
+
+	// A file edited only inside its regions strips to the generated file.
+	generated := "a\n// gokitgen:begin f\nreturn\n// gokitgen:end\nb\n"
+	if string(stripRegions([]byte(src))) != string(stripRegions([]byte(generated))) {
+		t.Error("region-only edits change the stripped content")
+	}
+}
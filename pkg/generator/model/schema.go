@@ -0,0 +1,375 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Schema is the table of a model as its migrations create it. The manifest
+// keeps the schema of every model, so a later run can migrate the table
+// from it instead of creating it again.
+type Schema struct {
+	Database string         `json:"database"`
+	Table    string         `json:"table"`
+	Columns  []SchemaColumn `json:"columns"`
+	Indexes  []SchemaIndex  `json:"indexes,omitempty"`
+}
+
+// SchemaColumn is a column of a Schema.
+type SchemaColumn struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	PrimaryKey bool     `json:"primary_key,omitempty"`
+	NotNull    bool     `json:"not_null,omitempty"`
+	Unique     bool     `json:"unique,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	Values     []string `json:"values,omitempty"`     // allowed values of an enum
+	References string   `json:"references,omitempty"` // table of a foreign key
+}
+
+// SchemaIndex is an index on a single column.
+type SchemaIndex struct {
+	Name   string `json:"name"`
+	Column string `json:"column"`
+	Unique bool   `json:"unique,omitempty"`
+}
+
+// schemaOf returns the table of c: the gorm.Model columns, one column per
+// field honouring the type, size, not null, unique and default settings of
+// its gorm tag, and the indexes GORM would create, named like GORM names
+// them.
+func schemaOf(c *ModelConfig) Schema {
+	dialect := c.Dialect()
+	table := tableName(c.ModelName)
+	timestamp := columnType(dialect, Field{Type: "time.Time"}, nil)
+
+	s := Schema{
+		Database: dialect,
+		Table:    table,
+		Columns: []SchemaColumn{
+			{Name: "id", Type: pick(dialect, "bigint unsigned", "bigserial", "integer"), PrimaryKey: true},
+			{Name: "created_at", Type: timestamp},
+			{Name: "updated_at", Type: timestamp},
+			{Name: "deleted_at", Type: timestamp},
+		},
+	}
+	index := func(unique bool, name, column string) {
+		// index:name,option keeps its options after the comma.
+		if name, _, _ = strings.Cut(name, ","); name == "" {
+			name = "idx_" + table + "_" + column
+		}
+		s.Indexes = append(s.Indexes, SchemaIndex{Name: name, Column: column, Unique: unique})
+	}
+	index(false, "", "deleted_at")
+
+	for _, f := range c.Fields {
+		settings := gormSettings(gormTag(c, f))
+		col := SchemaColumn{Name: columnName(f), Type: columnType(dialect, f, c.Enums)}
+		if size, ok := settings["size"]; ok && f.Type == "string" {
+			col.Type = "varchar(" + size + ")"
+		}
+		if t, ok := settings["type"]; ok {
+			col.Type = t
+		}
+		_, notNull := settings["not null"]
+		col.NotNull = notNull || !f.IsNullable
+		_, col.Unique = settings["unique"]
+		col.Default = settings["default"]
+		if f.TypeIsEnum {
+			col.Values = enumValues(f.Type, c.Enums)
+		}
+		if f.TypeIsRelation {
+			col.References = tableName(f.Type)
+		}
+		s.Columns = append(s.Columns, col)
+
+		tag := gormSettings(f.GormTag)
+		if f.TypeIsRelation {
+			tag["index"] = ""
+		}
+		if name, ok := tag["index"]; ok {
+			index(false, name, col.Name)
+		}
+		if name, ok := tag["uniqueindex"]; ok {
+			index(true, name, col.Name)
+		}
+	}
+	return s
+}
+
+// gormSettings splits a gorm tag such as "default:0;index;not null" into
+// lower-cased keys and their values.
+func gormSettings(tag string) map[string]string {
+	settings := make(map[string]string)
+	for _, part := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(part, ":")
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			settings[key] = strings.TrimSpace(value)
+		}
+	}
+	return settings
+}
+
+// quoteIdent quotes a table or column name for dialect.
+func quoteIdent(dialect, name string) string {
+	if dialect == DatabaseMySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// columnSQL is the definition of col in CREATE TABLE and ADD COLUMN.
+func columnSQL(dialect string, col SchemaColumn) string {
+	def := quoteIdent(dialect, col.Name) + " " + col.Type
+	if col.PrimaryKey {
+		return def + pick(dialect, " NOT NULL AUTO_INCREMENT PRIMARY KEY", " PRIMARY KEY", " PRIMARY KEY AUTOINCREMENT")
+	}
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	if col.Unique {
+		def += " UNIQUE"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	return def
+}
+
+// checkSQL is the constraint limiting an enum column to its values. MySQL
+// has native enums and needs none.
+func checkSQL(dialect, table string, col SchemaColumn) string {
+	if len(col.Values) == 0 || dialect == DatabaseMySQL {
+		return ""
+	}
+	values := make([]string, len(col.Values))
+	for i, v := range col.Values {
+		values[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s IN (%s))",
+		quoteIdent(dialect, checkName(table, col)), quoteIdent(dialect, col.Name), strings.Join(values, ", "))
+}
+
+// foreignKeySQL is the foreign key constraint of a relation column.
+func foreignKeySQL(dialect, table string, col SchemaColumn) string {
+	if col.References == "" {
+		return ""
+	}
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteIdent(dialect, foreignKeyName(table, col)), quoteIdent(dialect, col.Name),
+		quoteIdent(dialect, col.References), quoteIdent(dialect, "id"))
+}
+
+func indexSQL(dialect, table string, idx SchemaIndex) string {
+	kind := "INDEX"
+	if idx.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)", kind,
+		quoteIdent(dialect, idx.Name), quoteIdent(dialect, table), quoteIdent(dialect, idx.Column))
+}
+
+func checkName(table string, col SchemaColumn) string { return "chk_" + table + "_" + col.Name }
+
+func foreignKeyName(table string, col SchemaColumn) string { return "fk_" + table + "_" + col.Name }
+
+// uniqueName is the name the database gives the constraint of a UNIQUE
+// column.
+func uniqueName(dialect, table string, col SchemaColumn) string {
+	return pick(dialect, col.Name, table+"_"+col.Name+"_key", "")
+}
+
+// schemaChanges returns the statements migrating the table from old to new,
+// together with warnings about the statements that may lose data or fail on
+// existing rows and the changes the dialect cannot make in place.
+func schemaChanges(old, new Schema) (statements, warnings []string) {
+	dialect := new.Database
+	table := new.Table
+	q := func(name string) string { return quoteIdent(dialect, name) }
+	alter := func(format string, args ...any) {
+		statements = append(statements, "ALTER TABLE "+q(table)+" "+fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	// MySQL will not drop an index a foreign key needs, so the foreign keys
+	// of removed or changed relation columns go before the indexes.
+	if dialect == DatabaseMySQL {
+		for _, col := range old.Columns {
+			if next, ok := schemaColumn(new, col.Name); col.References != "" && (!ok || next.References != col.References) {
+				alter("DROP FOREIGN KEY %s", q(foreignKeyName(table, col)))
+			}
+		}
+	}
+
+	// Indexes go first, so the columns they cover can be dropped.
+	for _, idx := range old.Indexes {
+		if !slices.Contains(new.Indexes, idx) {
+			if dialect == DatabaseMySQL {
+				statements = append(statements, fmt.Sprintf("DROP INDEX %s ON %s", q(idx.Name), q(table)))
+			} else {
+				statements = append(statements, "DROP INDEX IF EXISTS "+q(idx.Name))
+			}
+		}
+	}
+
+	for _, col := range old.Columns {
+		if _, ok := schemaColumn(new, col.Name); ok {
+			continue
+		}
+		if dialect == DatabaseSQLite && (col.References != "" || col.Unique) {
+			warn("SQLite cannot drop %s.%s while it has a foreign key or UNIQUE constraint; rebuild the table by hand", table, col.Name)
+			continue
+		}
+		alter("DROP COLUMN %s", q(col.Name))
+		warn("DESTRUCTIVE: drops column %s.%s and all of its data", table, col.Name)
+	}
+
+	for _, col := range new.Columns {
+		prev, ok := schemaColumn(old, col.Name)
+		if !ok || columnsEqual(prev, col) {
+			continue
+		}
+		s, w := alterColumn(dialect, table, prev, col)
+		for _, stmt := range s {
+			alter("%s", stmt)
+		}
+		warnings = append(warnings, w...)
+	}
+
+	for _, col := range new.Columns {
+		if _, ok := schemaColumn(old, col.Name); ok {
+			continue
+		}
+		if col.NotNull && col.Default == "" && dialect != DatabaseMySQL {
+			warn("adds %s.%s as NOT NULL without a default, which fails if the table has rows", table, col.Name)
+		}
+		if dialect == DatabaseSQLite {
+			// SQLite only takes column constraints in ADD COLUMN and
+			// cannot add a UNIQUE column.
+			unique := col.Unique
+			col.Unique = false
+			def := columnSQL(dialect, col)
+			if check := checkSQL(dialect, table, col); check != "" {
+				def += " " + check
+			}
+			if col.References != "" {
+				def += fmt.Sprintf(" REFERENCES %s (%s)", q(col.References), q("id"))
+			}
+			alter("ADD COLUMN %s", def)
+			if unique {
+				statements = append(statements, indexSQL(dialect, table, SchemaIndex{Name: table + "_" + col.Name + "_key", Column: col.Name, Unique: true}))
+			}
+			continue
+		}
+		alter("ADD COLUMN %s", columnSQL(dialect, col))
+		if check := checkSQL(dialect, table, col); check != "" {
+			alter("ADD %s", check)
+		}
+		if fk := foreignKeySQL(dialect, table, col); fk != "" {
+			alter("ADD %s", fk)
+		}
+	}
+
+	for _, idx := range new.Indexes {
+		if !slices.Contains(old.Indexes, idx) {
+			statements = append(statements, indexSQL(dialect, table, idx))
+		}
+	}
+	return statements, warnings
+}
+
+// alterColumn returns the ALTER TABLE actions changing a column from old to
+// new and the warnings about them.
+func alterColumn(dialect, table string, old, new SchemaColumn) (actions, warnings []string) {
+	q := func(name string) string { return quoteIdent(dialect, name) }
+	column := table + "." + new.Name
+
+	// The type of an enum changes with its values, which are checked below.
+	if old.Type != new.Type && (len(old.Values) == 0 || len(new.Values) == 0) {
+		warnings = append(warnings, fmt.Sprintf("DESTRUCTIVE: changes the type of %s from %s to %s; values that do not convert are lost or fail the migration", column, old.Type, new.Type))
+	}
+	var removed []string
+	for _, v := range old.Values {
+		if !slices.Contains(new.Values, v) {
+			removed = append(removed, v)
+		}
+	}
+	if len(removed) > 0 {
+		warnings = append(warnings, fmt.Sprintf("DESTRUCTIVE: removes the values %s of %s; update the rows holding them first", strings.Join(removed, ", "), column))
+	}
+	if new.NotNull && !old.NotNull {
+		warnings = append(warnings, fmt.Sprintf("makes %s NOT NULL, which fails while it holds NULLs", column))
+	}
+
+	if dialect == DatabaseSQLite {
+		warnings = append(warnings, fmt.Sprintf("SQLite cannot alter %s in place; rebuild the table by hand", column))
+		return nil, warnings
+	}
+
+	// On MySQL schemaChanges has dropped the foreign key already.
+	if old.References != "" && old.References != new.References && dialect != DatabaseMySQL {
+		actions = append(actions, "DROP CONSTRAINT IF EXISTS "+q(foreignKeyName(table, old)))
+	}
+	if old.Unique && !new.Unique {
+		actions = append(actions, pick(dialect, "DROP INDEX ", "DROP CONSTRAINT IF EXISTS ", "")+q(uniqueName(dialect, table, old)))
+	}
+
+	if dialect == DatabaseMySQL {
+		// MODIFY restates the whole column; UNIQUE is kept out of it so an
+		// existing unique index is not created twice.
+		modified, unmodified := new, old
+		modified.Unique, unmodified.Unique, unmodified.References = false, false, new.References
+		if !columnsEqual(modified, unmodified) {
+			actions = append(actions, "MODIFY COLUMN "+columnSQL(dialect, modified))
+		}
+	} else {
+		if old.Type != new.Type {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", q(new.Name), new.Type, q(new.Name), new.Type))
+		}
+		switch {
+		case new.NotNull && !old.NotNull:
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", q(new.Name)))
+		case !new.NotNull && old.NotNull:
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", q(new.Name)))
+		}
+		switch {
+		case new.Default != "" && new.Default != old.Default:
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", q(new.Name), new.Default))
+		case new.Default == "" && old.Default != "":
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", q(new.Name)))
+		}
+		if !slices.Equal(old.Values, new.Values) {
+			if len(old.Values) > 0 {
+				actions = append(actions, "DROP CONSTRAINT IF EXISTS "+q(checkName(table, old)))
+			}
+			if check := checkSQL(dialect, table, new); check != "" {
+				actions = append(actions, "ADD "+check)
+			}
+		}
+	}
+
+	if new.Unique && !old.Unique {
+		actions = append(actions, fmt.Sprintf("ADD CONSTRAINT %s UNIQUE (%s)", q(uniqueName(dialect, table, new)), q(new.Name)))
+	}
+	if new.References != "" && old.References != new.References {
+		actions = append(actions, "ADD "+foreignKeySQL(dialect, table, new))
+	}
+	return actions, warnings
+}
+
+func schemaColumn(s Schema, name string) (SchemaColumn, bool) {
+	for _, col := range s.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return SchemaColumn{}, false
+}
+
+func columnsEqual(a, b SchemaColumn) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.PrimaryKey == b.PrimaryKey && a.NotNull == b.NotNull &&
+		a.Unique == b.Unique && a.Default == b.Default && slices.Equal(a.Values, b.Values) && a.References == b.References
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaChanges(t *testing.T) {
+	order := func(database string, fields ...Field) Schema {
+		return schemaOf(&ModelConfig{ModelName: "Order", Database: database,
+			Fields: append([]Field{{Name: "Title", Type: "string"}}, fields...)})
+	}
+	market := Field{Name: "Market", Type: "Market", TypeIsRelation: true}
+	tests := []struct {
+		name     string
+		old, new Schema
+		want     []string
+	}{
+		{
+			name: "mysql add relation",
+			old:  order(DatabaseMySQL),
+			new:  order(DatabaseMySQL, market),
+			want: []string{
+				"ALTER TABLE `orders` ADD COLUMN `market_id` bigint unsigned NOT NULL",
+				"ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_market_id` FOREIGN KEY (`market_id`) REFERENCES `markets` (`id`)",
+				"CREATE INDEX `idx_orders_market_id` ON `orders` (`market_id`)",
+			},
+		},
+		{
+			// MySQL refuses to drop an index a foreign key still needs.
+			name: "mysql drop relation",
+			old:  order(DatabaseMySQL, market),
+			new:  order(DatabaseMySQL),
+			want: []string{
+				"ALTER TABLE `orders` DROP FOREIGN KEY `fk_orders_market_id`",
+				"DROP INDEX `idx_orders_market_id` ON `orders`",
+				"ALTER TABLE `orders` DROP COLUMN `market_id`",
+			},
+		},
+		{
+			name: "mysql relation turned into a plain column",
+			old:  order(DatabaseMySQL, market),
+			new:  order(DatabaseMySQL, Field{Name: "MarketID", Type: "uint64"}),
+			want: []string{
+				"ALTER TABLE `orders` DROP FOREIGN KEY `fk_orders_market_id`",
+				"DROP INDEX `idx_orders_market_id` ON `orders`",
+			},
+		},
+		{
+			name: "postgres drop relation",
+			old:  order(DatabasePostgres, market),
+			new:  order(DatabasePostgres),
+			want: []string{
+				`DROP INDEX IF EXISTS "idx_orders_market_id"`,
+				`ALTER TABLE "orders" DROP COLUMN "market_id"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := schemaChanges(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("schemaChanges =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
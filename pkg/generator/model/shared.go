@@ -0,0 +1,372 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// sharedStruct is the struct of a shared file that has one field per model.
+const sharedStruct = "Endpoints"
+
+// mergeShared adds what the freshly rendered shared file (routes.go,
+// register.go) has on top of the existing one: imports, Endpoints fields and
+// the statements of the registration functions. Nothing is removed, so
+// models registered by earlier runs and hand-written routes are kept.
+//
+// The files are matched with go/ast, but the new code is spliced in as text
+// so the comments and layout of the existing file survive.
+func mergeShared(existing, rendered []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	oldFile, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	newFile, err := parser.ParseFile(fset, "rendered.go", rendered, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &sharedMerge{fset: fset, oldSrc: existing, newSrc: rendered}
+	if err := m.imports(oldFile, newFile); err != nil {
+		return nil, err
+	}
+
+	models, err := m.fields(oldFile, newFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range newFile.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		old := findFunc(oldFile, fn.Name.Name)
+		if old == nil {
+			return nil, fmt.Errorf("function %s not found", fn.Name.Name)
+		}
+		m.statements(old, fn, models)
+	}
+
+	if len(m.inserts) == 0 {
+		return existing, nil
+	}
+	return format.Source(m.apply())
+}
+
+// removeShared drops modelName from a shared file: its Endpoints field and
+// the statements of the registration functions that belong to it, together
+// with the comments and blank lines before them. Imports left unused are
+// removed as well.
+func removeShared(src []byte, modelName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "existing.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	st := findStruct(file, sharedStruct)
+	if st == nil {
+		return nil, fmt.Errorf("struct %s not found", sharedStruct)
+	}
+	m := &sharedMerge{fset: fset, oldSrc: src}
+
+	type cut struct{ from, to int }
+	var cuts []cut
+	bodyStarts := make(map[int]bool)
+	models := make(map[string]bool)
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			models[name.Name] = true
+			if name.Name == modelName {
+				cuts = append(cuts, cut{m.lineStart(src, field.Pos()), m.lineEnd(src, field.End())})
+			}
+		}
+	}
+	if !models[modelName] {
+		return src, nil
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		for i, owner := range stmtOwners(fn.Body.List, models) {
+			if owner != modelName {
+				continue
+			}
+			from := m.lineEnd(src, fn.Body.Lbrace)
+			if i > 0 {
+				from = m.lineEnd(src, fn.Body.List[i-1].End())
+			}
+			cuts = append(cuts, cut{from, m.lineEnd(src, fn.Body.List[i].End())})
+		}
+		bodyStarts[m.lineEnd(src, fn.Body.Lbrace)] = true
+	}
+
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].from < cuts[j].from })
+	var out bytes.Buffer
+	last, start := 0, -1
+	for _, c := range cuts {
+		if c.from > last {
+			out.Write(src[last:c.from])
+			start = c.from
+		}
+		last = max(last, c.to)
+		// A model cut from the top of a function takes the blank line that
+		// set the next model apart with it.
+		for bodyStarts[start] && last < len(src) && src[last] == '\n' {
+			last++
+		}
+	}
+	out.Write(src[last:])
+	return formatGo(out.Bytes(), "")
+}
+
+type sharedInsert struct {
+	offset int
+	text   string
+}
+
+type sharedMerge struct {
+	fset    *token.FileSet
+	oldSrc  []byte
+	newSrc  []byte
+	inserts []sharedInsert
+}
+
+func (m *sharedMerge) insert(offset int, text string) {
+	m.inserts = append(m.inserts, sharedInsert{offset: offset, text: text})
+}
+
+// apply splices the inserts into the existing source. Inserts at the same
+// offset keep the order they were added in.
+func (m *sharedMerge) apply() []byte {
+	sort.SliceStable(m.inserts, func(i, j int) bool { return m.inserts[i].offset < m.inserts[j].offset })
+	var out bytes.Buffer
+	last := 0
+	for _, ins := range m.inserts {
+		out.Write(m.oldSrc[last:ins.offset])
+		out.WriteString(ins.text)
+		last = ins.offset
+	}
+	out.Write(m.oldSrc[last:])
+	return out.Bytes()
+}
+
+func (m *sharedMerge) imports(oldFile, newFile *ast.File) error {
+	have := make(map[string]bool)
+	for _, spec := range oldFile.Imports {
+		have[spec.Path.Value] = true
+	}
+
+	var missing []string
+	for _, spec := range newFile.Imports {
+		if !have[spec.Path.Value] {
+			missing = append(missing, "\t"+m.text(m.newSrc, spec)+"\n")
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	for _, decl := range oldFile.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if !gen.Rparen.IsValid() {
+			// A single unparenthesized import: add a new block after it.
+			m.insert(m.offset(gen.End()), "\n\nimport (\n"+strings.Join(missing, "")+")")
+			return nil
+		}
+		m.insert(m.lineStart(m.oldSrc, gen.Rparen), strings.Join(missing, ""))
+		return nil
+	}
+	return fmt.Errorf("import block not found")
+}
+
+// fields adds the missing fields of the Endpoints struct and returns the
+// names of every model of the rendered file.
+func (m *sharedMerge) fields(oldFile, newFile *ast.File) (map[string]bool, error) {
+	newStruct := findStruct(newFile, sharedStruct)
+	oldStruct := findStruct(oldFile, sharedStruct)
+	if newStruct == nil || oldStruct == nil {
+		return nil, fmt.Errorf("struct %s not found", sharedStruct)
+	}
+
+	have := make(map[string]bool)
+	for _, field := range oldStruct.Fields.List {
+		for _, name := range field.Names {
+			have[name.Name] = true
+		}
+	}
+
+	models := make(map[string]bool)
+	var missing strings.Builder
+	for _, field := range newStruct.Fields.List {
+		for _, name := range field.Names {
+			models[name.Name] = true
+		}
+		if len(field.Names) > 0 && !have[field.Names[0].Name] {
+			missing.WriteString(m.lines(m.newSrc, field.Pos(), field.End()))
+		}
+	}
+	if missing.Len() > 0 {
+		m.insert(m.lineStart(m.oldSrc, oldStruct.Fields.Closing), missing.String())
+	}
+	return models, nil
+}
+
+// statements adds the statements of fn that old does not have yet. A new
+// statement goes right after the last existing statement of the same model,
+// or with its leading comment at the end of the function for a new model.
+func (m *sharedMerge) statements(old, fn *ast.FuncDecl, models map[string]bool) {
+	have := make(map[string]bool)
+	for _, stmt := range old.Body.List {
+		have[m.node(stmt)] = true
+	}
+	oldOwners := stmtOwners(old.Body.List, models)
+	newOwners := stmtOwners(fn.Body.List, models)
+
+	bodyEnd := m.lineStart(m.oldSrc, old.Body.Rbrace)
+	for i, stmt := range fn.Body.List {
+		if have[m.node(stmt)] {
+			continue
+		}
+
+		// Prefer the last statement of the model of the same kind, so new
+		// handler variables join the existing ones rather than the routes.
+		owner := newOwners[i]
+		anchor, sameKind := -1, -1
+		for j, o := range oldOwners {
+			if owner != "" && o == owner {
+				anchor = j
+				if reflect.TypeOf(old.Body.List[j]) == reflect.TypeOf(stmt) {
+					sameKind = j
+				}
+			}
+		}
+		if sameKind >= 0 {
+			anchor = sameKind
+		}
+		if anchor >= 0 {
+			m.insert(m.lineEnd(m.oldSrc, old.Body.List[anchor].End()), m.lines(m.newSrc, stmt.Pos(), stmt.End()))
+			continue
+		}
+
+		// Statements of a model the function does not know yet keep the
+		// blank lines and comments that precede them in the template. The
+		// first statement of the template has none, so a commented block
+		// gets a blank line to set it apart from the existing code.
+		start := fn.Body.Lbrace + 1
+		if i > 0 {
+			start = fn.Body.List[i-1].End()
+		}
+		text := string(m.newSrc[m.lineEnd(m.newSrc, start):m.lineEnd(m.newSrc, stmt.End())])
+		if i == 0 && len(old.Body.List) > 0 && strings.HasPrefix(strings.TrimSpace(text), "//") {
+			text = "\n" + text
+		}
+		m.insert(bodyEnd, text)
+	}
+}
+
+// stmtOwners maps each statement to the model it belongs to: the model whose
+// Endpoints field it selects, or whose variables it uses.
+func stmtOwners(stmts []ast.Stmt, models map[string]bool) []string {
+	owners := make([]string, len(stmts))
+	vars := make(map[string]string)
+	for i, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if owners[i] != "" {
+				return false
+			}
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if models[n.Sel.Name] {
+					owners[i] = n.Sel.Name
+				}
+			case *ast.Ident:
+				if owner, ok := vars[n.Name]; ok {
+					owners[i] = owner
+				}
+			}
+			return true
+		})
+		if assign, ok := stmt.(*ast.AssignStmt); ok && owners[i] != "" {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					vars[ident.Name] = owners[i]
+				}
+			}
+		}
+	}
+	return owners
+}
+
+func findStruct(file *ast.File, name string) *ast.StructType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == name {
+				return st
+			}
+		}
+	}
+	return nil
+}
+
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+func (m *sharedMerge) offset(pos token.Pos) int {
+	return m.fset.Position(pos).Offset
+}
+
+func (m *sharedMerge) text(src []byte, n ast.Node) string {
+	return string(src[m.offset(n.Pos()):m.offset(n.End())])
+}
+
+// node prints n in canonical form so statements compare equal regardless of
+// spacing.
+func (m *sharedMerge) node(n ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, m.fset, n)
+	return buf.String()
+}
+
+// lines returns the full source lines spanning pos to end.
+func (m *sharedMerge) lines(src []byte, pos, end token.Pos) string {
+	return string(src[m.lineStart(src, pos):m.lineEnd(src, end)])
+}
+
+func (m *sharedMerge) lineStart(src []byte, pos token.Pos) int {
+	return bytes.LastIndexByte(src[:m.offset(pos)], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line of pos.
+func (m *sharedMerge) lineEnd(src []byte, pos token.Pos) int {
+	off := m.offset(pos)
+	if i := bytes.IndexByte(src[off:], '\n'); i >= 0 {
+		return off + i + 1
+	}
+	return len(src)
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sharedRoutes builds a routes.go like the one the templates render, with
+// the given handlers (e.g. "Create") for each model.
+func sharedRoutes(t *testing.T, handlers []string, models ...string) string {
+	t.Helper()
+	var fields, body strings.Builder
+	for i, m := range models {
+		fmt.Fprintf(&fields, "\t%s endpoints.%sEndpoints\n", m, m)
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		fmt.Fprintf(&body, "\t// %s HTTP Transports\n", m)
+		for _, h := range handlers {
+			fmt.Fprintf(&body, "\t%s%sHandler := Make%s%sHandler(eps.%s.%sEndpoint)\n", strings.ToLower(h), m, h, m, m, h)
+		}
+		body.WriteString("\n")
+		for _, h := range handlers {
+			fmt.Fprintf(&body, "\tr.Handle(\"/%s/%s\", %s%sHandler)\n", strings.ToLower(m), strings.ToLower(h), strings.ToLower(h), m)
+		}
+	}
+	src := fmt.Sprintf(`package transports
+
+import (
+	"github.com/gorilla/mux"
+
+	"example.com/shop/internal/api/endpoints"
+)
+
+// Endpoints groups the endpoints of every model served over HTTP.
+type Endpoints struct {
+%s}
+
+func RegisterRoutes(r *mux.Router, eps Endpoints) {
+%s}
+`, fields.String(), body.String())
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	return string(out)
+}
+
+// withHealth adds a hand-written route at the end of RegisterRoutes.
+func withHealth(src string) string {
+	i := strings.LastIndex(src, "}\n")
+	return src[:i] + "\tr.Handle(\"/health\", health)\n" + src[i:]
+}
+
+func TestMergeShared(t *testing.T) {
+	create := []string{"Create"}
+	both := []string{"Create", "Get"}
+	tests := []struct {
+		name     string
+		existing string
+		rendered string
+		want     string
+	}{
+		{
+			name:     "new model",
+			existing: sharedRoutes(t, create, "Order"),
+			rendered: sharedRoutes(t, create, "Product"),
+			want:     sharedRoutes(t, create, "Order", "Product"),
+		},
+		{
+			name:     "new operation of a known model",
+			existing: sharedRoutes(t, create, "Order"),
+			rendered: sharedRoutes(t, both, "Order"),
+			want:     sharedRoutes(t, both, "Order"),
+		},
+		{
+			name:     "nothing new",
+			existing: sharedRoutes(t, create, "Order", "Product"),
+			rendered: sharedRoutes(t, create, "Product"),
+			want:     sharedRoutes(t, create, "Order", "Product"),
+		},
+		{
+			name:     "hand-written routes kept",
+			existing: withHealth(sharedRoutes(t, create, "Order")),
+			rendered: sharedRoutes(t, create, "Product"),
+			want: strings.Replace(sharedRoutes(t, create, "Order", "Product"),
+				"\n\t// Product", "\tr.Handle(\"/health\", health)\n\n\t// Product", 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeShared([]byte(tt.existing), []byte(tt.rendered))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeShared =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveShared(t *testing.T) {
+	both := []string{"Create", "Get"}
+	tests := []struct {
+		name  string
+		src   string
+		model string
+		want  string
+	}{
+		{"last model", sharedRoutes(t, both, "Order", "Product"), "Product", sharedRoutes(t, both, "Order")},
+		{"first model", sharedRoutes(t, both, "Order", "Product"), "Order", sharedRoutes(t, both, "Product")},
+		{"middle model", sharedRoutes(t, both, "A", "B", "C"), "B", sharedRoutes(t, both, "A", "C")},
+		{"unknown model", sharedRoutes(t, both, "Order"), "Product", sharedRoutes(t, both, "Order")},
+		{
+			name:  "hand-written routes kept",
+			src:   withHealth(sharedRoutes(t, both, "Order", "Product")),
+			model: "Product",
+			want:  withHealth(sharedRoutes(t, both, "Order")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := removeShared([]byte(tt.src), tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("removeShared =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStmtOwners(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "routes.go", sharedRoutes(t, []string{"Create"}, "Order", "Product"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := findFunc(file, "RegisterRoutes")
+	got := stmtOwners(fn.Body.List, map[string]bool{"Order": true, "Product": true})
+	// Handlers select the model's endpoints, routes use the handlers.
+	want := []string{"Order", "Order", "Product", "Product"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stmtOwners = %q, want %q", got, want)
+	}
+}
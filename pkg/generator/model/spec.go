@@ -0,0 +1,125 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSpec reads a model spec file and validates it. The format is picked
+// from the extension: .yaml/.yml for YAML, .json for JSON.
+func LoadSpec(path string) (*ModelConfig, error) {
+	config, err := ReadSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ReadSpec reads a model spec file like LoadSpec but leaves validation to
+// the caller, which may complete the model first, e.g. from flags.
+func ReadSpec(path string) (*ModelConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ModelConfig{}
+	if err := decodeSpec(path, data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+
+	config.normalize()
+	return config, nil
+}
+
+// SaveSpec writes config as a spec file that LoadSpec can read back.
+func SaveSpec(path string, config *ModelConfig) error {
+	return writeSpec(path, config)
+}
+
+func writeSpec(path string, v any) error {
+	data, err := encodeSpec(path, v)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func decodeSpec(path string, data []byte, v any) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		return dec.Decode(v)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	default:
+		return fmt.Errorf("unsupported spec format %q (use .yaml, .yml or .json)", ext)
+	}
+}
+
+func encodeSpec(path string, v any) ([]byte, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	case ".json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported spec format %q (use .yaml, .yml or .json)", ext)
+	}
+}
+
+// normalize fills in what a hand-written spec may leave implicit: the
+// "Ref:" prefix on relation types, enum flags, the case of operation names
+// and the output path.
+func (c *ModelConfig) normalize() {
+	c.Database = strings.ToLower(c.Database)
+	for i, op := range c.Operations {
+		c.Operations[i] = Operation(strings.ToLower(string(op)))
+	}
+	for i, f := range c.Fields {
+		if f.TypeIsRelation {
+			c.Fields[i].Type = strings.TrimPrefix(f.Type, "Ref:")
+			continue
+		}
+		if f.TypeIsEnum {
+			continue
+		}
+
+		resolved := NewField(f.Name, f.Type, c.Enums)
+		c.Fields[i].Type = resolved.Type
+		c.Fields[i].TypeIsEnum = resolved.TypeIsEnum
+		c.Fields[i].TypeIsRelation = resolved.TypeIsRelation
+	}
+
+	if c.OutputPath == "" {
+		c.OutputPath = "./"
+	}
+}
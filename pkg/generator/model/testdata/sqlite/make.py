@@ -0,0 +1,50 @@
+# Builds the SQLite databases import_sqlite_test.go reads:
+#
+#   python3 make.py
+#
+# 1 KiB pages keep the files small and make multi-page schemas cheap.
+import os
+import shutil
+import sqlite3
+
+here = os.path.dirname(os.path.abspath(__file__))
+
+
+def create(name, journal="delete"):
+    path = os.path.join(here, name)
+    for suffix in ("", "-wal", "-shm"):
+        if os.path.exists(path + suffix):
+            os.remove(path + suffix)
+    db = sqlite3.connect(path)
+    db.execute("PRAGMA page_size = 1024")
+    db.execute("PRAGMA journal_mode = " + journal)
+    return path, db
+
+
+# Enough tables for sqlite_master to span interior and leaf pages.
+path, db = create("pages.db")
+for i in range(40):
+    db.execute(f"CREATE TABLE table_{i:02} (id integer PRIMARY KEY, name varchar(100) NOT NULL, note text)")
+db.commit()
+db.close()
+
+# A CREATE TABLE statement longer than a page is stored in overflow pages.
+path, db = create("overflow.db")
+columns = ", ".join(f"column_{i:03} varchar(40) NOT NULL DEFAULT 'value {i}'" for i in range(60))
+db.execute(f"CREATE TABLE wide (id integer PRIMARY KEY, {columns})")
+db.commit()
+db.close()
+
+# The tables live in the log only: the database is copied while it is still
+# open, before SQLite checkpoints the log into it.
+path, db = create("wal.db", journal="wal")
+db.execute("PRAGMA wal_autocheckpoint = 0")
+db.execute("CREATE TABLE markets (id integer PRIMARY KEY, name text NOT NULL)")
+db.commit()
+db.execute("CREATE TABLE orders (id integer PRIMARY KEY, market_id integer REFERENCES markets(id), total integer)")
+db.commit()
+for suffix in ("", "-wal"):
+    shutil.copy(path + suffix, path + suffix + ".tmp")
+db.close()
+for suffix in ("", "-wal"):
+    os.replace(path + suffix + ".tmp", path + suffix)
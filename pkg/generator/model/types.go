@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// scalarTypes are the Go types that map to a protobuf scalar of the same
+// Go type, so they can be the elements of slices and the values of maps.
+var scalarTypes = []string{"string", "bool", "int32", "int64", "uint32", "uint64", "float32", "float64"}
+
+// FieldTypes lists the types a field can have besides enums, relations,
+// []T and map[string]T of scalarTypes.
+var FieldTypes = []string{
+	"string", "bool",
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+	"time.Time", "time.Duration", "uuid.UUID", "decimal.Decimal",
+	"[]byte", "json.RawMessage",
+}
+
+// ValidateType checks that the generator knows how to store, send and map
+// values of goType.
+func ValidateType(goType string) error {
+	if slices.Contains(FieldTypes, goType) {
+		return nil
+	}
+	if elem, ok := strings.CutPrefix(goType, "[]"); ok && slices.Contains(scalarTypes, elem) {
+		return nil
+	}
+	if value, ok := strings.CutPrefix(goType, "map[string]"); ok && slices.Contains(scalarTypes, value) {
+		return nil
+	}
+	return fmt.Errorf("unsupported type %q (expected one of %s, []T or map[string]T of %s, an enum or Ref:Model)",
+		goType, strings.Join(FieldTypes, ", "), strings.Join(scalarTypes, ", "))
+}
+
+// isNilable reports whether goType is a slice or a map, including []byte
+// and json.RawMessage, which stand for NULL with nil and so are never
+// pointers.
+func isNilable(goType string) bool {
+	return goType == "json.RawMessage" || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// Pointer reports whether the field is a pointer in the generated Go code:
+// it is nullable and not a slice or a map.
+func (f Field) Pointer() bool {
+	return f.IsNullable && (f.TypeIsEnum || f.TypeIsRelation || !isNilable(f.Type))
+}
@@ -1,21 +1,38 @@
 package model
 
 import (
-	"text/template"
+	"fmt"
+	"slices"
 	"strings"
+	"text/template"
 	"unicode"
 )
 
-
-
 func TemplateFuncMap() template.FuncMap {
 	return template.FuncMap{
-		"lower":        strings.ToLower,
-		"join":         func(ss []string, sep string) string { return strings.Join(ss, sep) },
-		"title":        func(s string) string { if s == "" { return "" }; r := []rune(s); r[0] = unicode.ToUpper(r[0]); return string(r) },
-		"toPascal":     toPascal,
-		"protobufType": protobufType,
-		"addIndex":     addIndex,
+		"lower":          strings.ToLower,
+		"join":           func(ss []string, sep string) string { return strings.Join(ss, sep) },
+		"title":          title,
+		"toPascal":       toPascal,
+		"camel":          camel,
+		"snake":          snake,
+		"protobufType":   protobufType,
+		"protoGoType":    protoGoType,
+		"protoLabel":     protoLabel,
+		"protoAssign":    protoAssign,
+		"protoGoName":    protoGoName,
+		"protoEnumValue": protoEnumValue,
+		"assign":         assign,
+		"sampleValue":    sampleValue,
+		"addIndex":       addIndex,
+		"tableName":      tableName,
+		"columnName":     columnName,
+		"columnType":     columnType,
+		"isJSONColumn":   isJSONColumn,
+		"gormTag":        gormTag,
+		"quoteIdent":     quoteIdent,
+		"createColumns":  createColumns,
+		"createIndexes":  createIndexes,
 	}
 }
 
@@ -30,21 +47,244 @@ func addIndex(index interface{}, offset int) int {
 	}
 }
 
+// protobufType returns the protobuf type of the field of goType, including
+// the repeated label of slices, e.g. "repeated string", or "" when the type
+// has no protobuf mapping.
 func protobufType(goType string) string {
 	switch goType {
-	case "string":
+	case "string", "uuid.UUID", "decimal.Decimal", "json.RawMessage":
 		return "string"
-	case "int", "int32", "int64":
+	case "int", "int64":
+		return "int64"
+	case "int8", "int16", "int32":
 		return "int32"
-	case "uint", "uint32", "uint64":
+	case "uint", "uint64":
+		return "uint64"
+	case "uint8", "uint16", "uint32":
 		return "uint32"
 	case "bool":
 		return "bool"
-	case "float32", "float64":
+	case "float32":
 		return "float"
-	default:
-		return "string" // fallback
+	case "float64":
+		return "double"
+	case "[]byte":
+		return "bytes"
+	case "time.Time":
+		return "google.protobuf.Timestamp"
+	case "time.Duration":
+		return "google.protobuf.Duration"
+	}
+	if elem, ok := strings.CutPrefix(goType, "[]"); ok && slices.Contains(scalarTypes, elem) {
+		return "repeated " + protobufType(elem)
+	}
+	if value, ok := strings.CutPrefix(goType, "map[string]"); ok && slices.Contains(scalarTypes, value) {
+		return "map<string, " + protobufType(value) + ">"
+	}
+	return ""
+}
+
+// protoLabel returns the "optional " label of a field that is a pointer in
+// Go. Message fields tell a missing value by nil already.
+func protoLabel(f Field) string {
+	if !f.Pointer() || (!f.TypeIsEnum && !f.TypeIsRelation && protoGoType(f.Type) == "*") {
+		return ""
+	}
+	return "optional "
+}
+
+// protoGoType returns the Go type protoc-gen-go uses for the protobuf field
+// of goType, "*" for the well-known message types, or "" when the type has
+// no protobuf mapping.
+func protoGoType(goType string) string {
+	switch goType {
+	case "string", "int32", "int64", "uint32", "uint64", "bool", "float32", "float64", "[]byte":
+		return goType
+	case "int":
+		return "int64"
+	case "int8", "int16":
+		return "int32"
+	case "uint":
+		return "uint64"
+	case "uint8", "uint16":
+		return "uint32"
+	case "uuid.UUID", "decimal.Decimal", "json.RawMessage":
+		return "string"
+	case "time.Time", "time.Duration":
+		return "*"
+	}
+	if isNilable(goType) && protobufType(goType) != "" {
+		return goType
+	}
+	return ""
+}
+
+// protoGoName returns the name protoc-gen-go gives the Go field of a
+// snake_case protobuf field, e.g. market_id -> MarketId.
+func protoGoName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		parts[i] = title(part)
+	}
+	return strings.Join(parts, "")
+}
+
+// protoEnumValue returns the protobuf name of an enum value, prefixed with
+// the enum name as the protobuf style guide asks, e.g. ORDER_STATUS_PENDING.
+func protoEnumValue(enum, value string) string {
+	r := strings.NewReplacer("-", "_", " ", "_")
+	return strings.ToUpper(snake(enum) + "_" + r.Replace(value))
+}
+
+// assign renders dst = conv(src) for the mapper templates. An empty conv
+// copies the value; nullable values are converted through their pointer.
+func assign(dst, src, conv string, nullable bool) string {
+	switch {
+	case conv == "":
+		return dst + " = " + src
+	case !nullable:
+		return fmt.Sprintf("%s = %s(%s)", dst, conv, src)
+	}
+	return fmt.Sprintf("if %s != nil {\nv := %s(*%s)\n%s = &v\n}", src, conv, src, dst)
+}
+
+// protoAssign renders the statements copying a plain field of goType
+// between the model and its protobuf message, converting the types
+// protoc-gen-go generates differently. pointer is Field.Pointer. From the
+// message, a UUID, decimal or JSON document that does not parse makes the
+// mapper return an error naming field; an empty string is an unset field
+// and leaves the zero value.
+func protoAssign(dst, src, field, goType string, pointer, toProto bool) string {
+	// call renders dst = fn(src), through a copy when src is a pointer.
+	call := func(fn string) string {
+		if !pointer {
+			return fmt.Sprintf("%s = %s(%s)", dst, fn, src)
+		}
+		return fmt.Sprintf("if %s != nil {\nv := %s(*%s)\n%s = &v\n}", src, fn, src, dst)
 	}
+	if toProto {
+		switch goType {
+		case "time.Time", "time.Duration":
+			pkg := "timestamppb"
+			if goType == "time.Duration" {
+				pkg = "durationpb"
+			}
+			if pointer {
+				return fmt.Sprintf("if %s != nil {\n%s = %s.New(*%s)\n}", src, dst, pkg, src)
+			}
+			return fmt.Sprintf("%s = %s.New(%s)", dst, pkg, src)
+		case "uuid.UUID", "decimal.Decimal":
+			if pointer {
+				return fmt.Sprintf("if %s != nil {\nv := %s.String()\n%s = &v\n}", src, src, dst)
+			}
+			return fmt.Sprintf("%s = %s.String()", dst, src)
+		}
+		if conv := protoGoType(goType); conv != goType {
+			return call(conv)
+		}
+		return dst + " = " + src
+	}
+
+	switch goType {
+	case "time.Time", "time.Duration":
+		method := "AsTime"
+		if goType == "time.Duration" {
+			method = "AsDuration"
+		}
+		if pointer {
+			return fmt.Sprintf("if %s != nil {\nv := %s.%s()\n%s = &v\n}", src, src, method, dst)
+		}
+		return fmt.Sprintf("if %s != nil {\n%s = %s.%s()\n}", src, dst, src, method)
+	case "uuid.UUID", "decimal.Decimal":
+		parse := "uuid.Parse"
+		if goType == "decimal.Decimal" {
+			parse = "decimal.NewFromString"
+		}
+		fail := fmt.Sprintf("if err != nil {\nreturn nil, fmt.Errorf(\"%s: %%w\", err)\n}", field)
+		if pointer {
+			return fmt.Sprintf("if %s != nil {\nv, err := %s(*%s)\n%s\n%s = &v\n}", src, parse, src, fail, dst)
+		}
+		return fmt.Sprintf("if %s != \"\" {\nv, err := %s(%s)\n%s\n%s = v\n}", src, parse, src, fail, dst)
+	case "json.RawMessage":
+		// An empty string is no document at all: an empty RawMessage would
+		// not marshal, so the field is left nil.
+		return fmt.Sprintf("if %s != \"\" {\nif !json.Valid([]byte(%s)) {\nreturn nil, errors.New(\"%s: invalid JSON\")\n}\n%s = json.RawMessage(%s)\n}", src, src, field, dst, src)
+	}
+	if protoGoType(goType) != goType {
+		return call(goType)
+	}
+	return dst + " = " + src
+}
+
+// sampleValue returns a Go expression of type goType for generated tests, or
+// "" when there is no obvious sample for the type.
+func sampleValue(goType string) string {
+	switch goType {
+	case "string":
+		return `"sample"`
+	case "bool":
+		return "true"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return goType + "(7)"
+	case "float32", "float64":
+		return goType + "(1.5)"
+	case "time.Time":
+		return "time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)"
+	case "time.Duration":
+		return "90 * time.Second"
+	case "uuid.UUID":
+		return `uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")`
+	case "decimal.Decimal":
+		return `decimal.RequireFromString("19.99")`
+	case "[]byte":
+		return `[]byte("sample")`
+	case "json.RawMessage":
+		return "json.RawMessage(`{\"key\":\"value\"}`)"
+	}
+	if elem, ok := strings.CutPrefix(goType, "[]"); ok && sampleValue(elem) != "" {
+		return goType + "{" + sampleValue(elem) + "}"
+	}
+	if value, ok := strings.CutPrefix(goType, "map[string]"); ok && sampleValue(value) != "" {
+		return goType + `{"key": ` + sampleValue(value) + "}"
+	}
+	return ""
+}
+
+func title(s string) string {
+	if s == "" {
+		return ""
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// camel lower-cases the first letter, e.g. OrderItem -> orderItem.
+func camel(s string) string {
+	if s == "" {
+		return ""
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// snake converts a Go name to snake_case, keeping initialisms together,
+// e.g. MarketID -> market_id, HTTPServer -> http_server.
+func snake(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) && i > 0 {
+			prev := r[i-1]
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
 }
 
 func toPascal(s string) string {
@@ -63,4 +303,4 @@ func toPascal(s string) string {
 		parts[i] = string(r)
 	}
 	return strings.Join(parts, "")
-}
\ No newline at end of file
+}
@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestProtoAssign(t *testing.T) {
+	tests := []struct {
+		goType  string
+		pointer bool
+		toProto bool
+		want    string
+	}{
+		{"string", false, true, "p.X = m.X"},
+		{"int", false, true, "p.X = int64(m.X)"},
+		{"int", true, false, "if p.X != nil {\nv := int(*p.X)\nm.X = &v\n}"},
+		{"time.Time", false, true, "p.X = timestamppb.New(m.X)"},
+		{"time.Time", false, false, "if p.X != nil {\nm.X = p.X.AsTime()\n}"},
+		// A value that does not parse is an error, an empty string is unset.
+		{"uuid.UUID", false, false, "if p.X != \"\" {\nv, err := uuid.Parse(p.X)\nif err != nil {\nreturn nil, fmt.Errorf(\"x: %w\", err)\n}\nm.X = v\n}"},
+		{"decimal.Decimal", true, false, "if p.X != nil {\nv, err := decimal.NewFromString(*p.X)\nif err != nil {\nreturn nil, fmt.Errorf(\"x: %w\", err)\n}\nm.X = &v\n}"},
+		{"decimal.Decimal", true, true, "if m.X != nil {\nv := m.X.String()\np.X = &v\n}"},
+		{"json.RawMessage", false, true, "p.X = string(m.X)"},
+		// An empty document stays nil, which marshals, unlike an empty RawMessage.
+		{"json.RawMessage", false, false, "if p.X != \"\" {\nif !json.Valid([]byte(p.X)) {\nreturn nil, errors.New(\"x: invalid JSON\")\n}\nm.X = json.RawMessage(p.X)\n}"},
+		{"[]string", false, false, "m.X = p.X"},
+	}
+	for _, tt := range tests {
+		dst, src := "m.X", "p.X"
+		if tt.toProto {
+			dst, src = src, dst
+		}
+		if got := protoAssign(dst, src, "x", tt.goType, tt.pointer, tt.toProto); got != tt.want {
+			t.Errorf("protoAssign(%s, pointer %v, toProto %v) = %q, want %q", tt.goType, tt.pointer, tt.toProto, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package model
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VerifyError lists everything wrong with the generated code.
+type VerifyError struct {
+	Errors []*TemplateError
+}
+
+func (e *VerifyError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("%d problem(s) in the generated code:\n%s", len(e.Errors), strings.Join(lines, "\n"))
+}
+
+// Verify renders the files for configs as Generate would write them and
+// type-checks the Go packages they form, together with any other Go files
+// already in their directories on disk. Packages outside the generated
+// project (go-kit, gorm, code generated by protoc, ...) are not available
+// offline, so uses of them are trusted; everything else must compile.
+// Problems are reported as a *VerifyError naming the template and line
+// that produced them.
+func (g *Generator) Verify(configs ...*ModelConfig) error {
+	files, _, err := g.Plan(configs...)
+	if err != nil {
+		return err
+	}
+
+	v := &verifier{
+		fset:     token.NewFileSet(),
+		std:      importer.ForCompiler(token.NewFileSet(), "source", nil),
+		module:   configs[0].ModulePath,
+		dirs:     make(map[string][]*verifyFile),
+		checked:  make(map[string]*types.Package),
+		external: make(map[string]*types.Package),
+		byName:   make(map[string]*verifyFile),
+		seen:     make(map[string]bool),
+	}
+	if err := v.load(files, configs[0].OutputPath); err != nil {
+		return err
+	}
+
+	importPaths := make([]string, 0, len(v.dirs))
+	for p := range v.dirs {
+		importPaths = append(importPaths, p)
+	}
+	sort.Strings(importPaths)
+	for _, p := range importPaths {
+		v.check(p, false)
+		v.check(p, true)
+	}
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].Path != v.errors[j].Path {
+			return v.errors[i].Path < v.errors[j].Path
+		}
+		return v.errors[i].Line < v.errors[j].Line
+	})
+	return &VerifyError{Errors: v.errors}
+}
+
+type verifyFile struct {
+	name     string // path on disk
+	template string // empty for files that were not generated
+	content  []byte
+	ast      *ast.File
+}
+
+type verifier struct {
+	fset     *token.FileSet
+	std      types.Importer
+	module   string
+	dirs     map[string][]*verifyFile // by import path
+	checked  map[string]*types.Package
+	external map[string]*types.Package
+	byName   map[string]*verifyFile
+	seen     map[string]bool // reported positions
+	errors   []*TemplateError
+}
+
+func (v *verifier) load(files []GeneratedFile, outputPath string) error {
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+		v.addFile(&verifyFile{name: f.Path, template: f.Template, content: f.Content}, outputPath)
+		dirs[filepath.Dir(f.Path)] = true
+	}
+
+	// Hand-written files next to the generated ones belong to the same
+	// packages.
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := filepath.Join(dir, e.Name())
+			if e.IsDir() || !strings.HasSuffix(name, ".go") || v.byName[name] != nil {
+				continue
+			}
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			v.addFile(&verifyFile{name: name, content: content}, outputPath)
+		}
+	}
+	return nil
+}
+
+func (v *verifier) addFile(f *verifyFile, outputPath string) {
+	parsed, err := parser.ParseFile(v.fset, f.name, f.content, parser.ParseComments)
+	if err != nil {
+		v.report(f, err)
+		return
+	}
+	f.ast = parsed
+	v.byName[f.name] = f
+
+	rel, err := filepath.Rel(outputPath, filepath.Dir(f.name))
+	if err != nil {
+		rel = filepath.Dir(f.name)
+	}
+	importPath := path.Join(v.module, filepath.ToSlash(rel))
+	v.dirs[importPath] = append(v.dirs[importPath], f)
+}
+
+// Import implements types.Importer for the packages of the generated
+// project, the standard library and stand-ins for everything else.
+func (v *verifier) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := v.checked[importPath]; ok {
+		return pkg, nil
+	}
+	if _, ok := v.dirs[importPath]; ok {
+		return v.check(importPath, false), nil
+	}
+	if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") && !strings.HasPrefix(importPath, v.module+"/") {
+		if pkg, err := v.std.Import(importPath); err == nil {
+			return pkg, nil
+		}
+	}
+
+	pkg, ok := v.external[importPath]
+	if !ok {
+		pkg = types.NewPackage(importPath, importName(importPath))
+		pkg.MarkComplete()
+		v.external[importPath] = pkg
+	}
+	return pkg, nil
+}
+
+// check type-checks the package at importPath. Without tests only the
+// regular files are checked, which is what importers of the package see;
+// with tests the _test.go files are checked on top of them, in the package
+// itself and in an external _test package.
+func (v *verifier) check(importPath string, tests bool) *types.Package {
+	if !tests {
+		if pkg, ok := v.checked[importPath]; ok {
+			return pkg
+		}
+	}
+
+	groups := make(map[string][]*verifyFile)
+	for _, f := range v.dirs[importPath] {
+		isTest := strings.HasSuffix(f.name, "_test.go")
+		if isTest && !tests {
+			continue
+		}
+		groups[f.ast.Name.Name] = append(groups[f.ast.Name.Name], f)
+	}
+
+	var result *types.Package
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		group := groups[name]
+		if tests && !strings.HasSuffix(name, "_test") {
+			hasTest := false
+			for _, f := range group {
+				hasTest = hasTest || strings.HasSuffix(f.name, "_test.go")
+			}
+			if !hasTest {
+				continue
+			}
+		}
+
+		files := make([]*ast.File, len(group))
+		for i, f := range group {
+			files[i] = f.ast
+		}
+		var errs []error
+		conf := types.Config{
+			Importer: v,
+			Error:    func(err error) { errs = append(errs, err) },
+		}
+		info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+		pkg, _ := conf.Check(importPath, v.fset, files, info)
+		for _, err := range errs {
+			v.typeError(err, info)
+		}
+		if !tests && !strings.HasSuffix(name, "_test") {
+			result = pkg
+			v.checked[importPath] = pkg
+		}
+	}
+	if result == nil && !tests {
+		result = types.NewPackage(importPath, path.Base(importPath))
+		v.checked[importPath] = result
+	}
+	return result
+}
+
+func (v *verifier) typeError(err error, info *types.Info) {
+	terr, ok := err.(types.Error)
+	if !ok {
+		return
+	}
+	pos := v.fset.Position(terr.Pos)
+	f := v.byName[pos.Filename]
+	if f == nil || v.usesExternal(f, terr.Pos) || v.promotedExternal(f, terr.Pos, info) {
+		return
+	}
+	if strings.HasPrefix(terr.Msg, "declared and not used") && mentionedAgain(f.ast, terr.Pos) {
+		return
+	}
+	if strings.HasSuffix(terr.Msg, "imported and not used") && importMentioned(f.ast, terr.Pos) {
+		return
+	}
+	v.addError(f, pos.Line, terr.Msg)
+}
+
+// promotedExternal reports whether pos is the field of a selector on a
+// struct that embeds a type of a package that is not available, such as
+// gorm.Model; the fields promoted from it cannot be checked.
+func (v *verifier) promotedExternal(f *verifyFile, pos token.Pos, info *types.Info) bool {
+	var sel *ast.SelectorExpr
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		if s, ok := n.(*ast.SelectorExpr); ok && s.Sel.Pos() == pos {
+			sel = s
+		}
+		return sel == nil
+	})
+	if sel == nil || info.Types[sel.X].Type == nil {
+		return false
+	}
+
+	t := info.Types[sel.X].Type
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		ft := field.Type()
+		if p, ok := ft.(*types.Pointer); ok {
+			ft = p.Elem()
+		}
+		if ft == types.Typ[types.Invalid] {
+			return true
+		}
+		if named, ok := ft.(*types.Named); ok && named.Obj().Pkg() != nil {
+			if _, ok := v.external[named.Obj().Pkg().Path()]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usesExternal reports whether pos lies in a selector on a package that is
+// not available, whose members cannot be checked.
+func (v *verifier) usesExternal(f *verifyFile, pos token.Pos) bool {
+	names := make(map[string]bool)
+	for _, spec := range f.ast.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if _, ok := v.external[p]; !ok {
+			continue
+		}
+		name := importName(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		names[name] = true
+	}
+
+	found := false
+	ast.Inspect(f.ast, func(n ast.Node) bool {
+		if found || n == nil || pos < n.Pos() || pos >= n.End() {
+			return false
+		}
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && names[ident.Name] {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// mentionedAgain reports whether the variable declared at pos appears again
+// in its function. go/types does not look at the arguments of a call whose
+// function comes from an unavailable package, so variables and imports used
+// only there look unused.
+func mentionedAgain(file *ast.File, pos token.Pos) bool {
+	var fn *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if d, ok := decl.(*ast.FuncDecl); ok && d.Body != nil && d.Pos() <= pos && pos < d.End() {
+			fn = d
+		}
+	}
+	if fn == nil {
+		return false
+	}
+
+	var name string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Pos() == pos {
+			name = ident.Name
+		}
+		return name == ""
+	})
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name && ident.Pos() > pos {
+			found = true
+		}
+		return !found
+	})
+	return name != "" && found
+}
+
+// importMentioned reports whether the package imported at pos is used by
+// any selector of the file; see mentionedAgain.
+func importMentioned(file *ast.File, pos token.Pos) bool {
+	name := ""
+	for _, spec := range file.Imports {
+		if spec.Pos() <= pos && pos < spec.End() {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			name = importName(p)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+		}
+	}
+
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name {
+				found = true
+			}
+		}
+		return !found
+	})
+	return name != "" && found
+}
+
+func (v *verifier) report(f *verifyFile, err error) {
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		v.addError(f, list[0].Pos.Line, list[0].Msg)
+		return
+	}
+	v.errors = append(v.errors, &TemplateError{Path: f.name, Template: f.template, Msg: err.Error()})
+}
+
+func (v *verifier) addError(f *verifyFile, line int, msg string) {
+	key := fmt.Sprintf("%s:%d:%s", f.name, line, msg)
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.errors = append(v.errors, newTemplateError(f.template, f.name, f.content, line, msg))
+}
@@ -1,213 +1,720 @@
 package model
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrAborted is returned by RunWizard when the user quits before generating.
+var ErrAborted = errors.New("wizard aborted")
+
+type wizardStep int
+
+const (
+	stepName wizardStep = iota
+	stepModule
+	stepOutput
+	stepEnums
+	stepEnumName
+	stepEnumValues
+	stepFields
+	stepFieldName
+	stepFieldType
+	stepFieldCustomType
+	stepFieldNullable
+	stepFieldValidation
+	stepFieldCustomValidation
+	stepFieldGorm
+	stepFieldComment
+	stepOperations
+	stepTransport
+	stepDatabase
+	stepTests
+	stepSave
+	stepPreview
+	stepConfirm
 )
 
-type Field struct {
-	Name           string
-	Type           string
-	TypeIsEnum     bool
-	TypeIsRelation bool
-	IsNullable     bool
-	Validation     []string
-	GormTag        string // e.g., "default:0", "index", "unique"
-	Comment        string
+const customTypeOption = "Custom…"
+
+var builtinTypes = []string{
+	"string", "int", "int32", "int64", "uint", "uint32", "uint64", "bool", "float32", "float64",
+	"time.Time", "time.Duration", "uuid.UUID", "decimal.Decimal",
+	"[]byte", "[]string", "map[string]string", "json.RawMessage",
 }
 
-type Enum struct {
-	Name   string
-	Values []string
+var validationOptions = []string{
+	"required", "omitempty", "email", "url", "uuid", "min=1", "max=255", "gt=0", "gte=0",
 }
 
-type ModelConfig struct {
-	ModelName      string
-	ModulePath 	   string
-	Fields         []Field
-	Enums          []Enum
-	GenerateHTTP   bool
-	GenerategRPC   bool
-	GenerateTests  bool
-	OutputPath     string
+var transportOptions = []string{"HTTP", "gRPC", "HTTP + gRPC"}
+
+// wizard is the Bubble Tea model behind RunWizard. Every screen is a step;
+// moving forward pushes the current step on history and esc pops it, so
+// going back always returns to the screen the user actually came from.
+type wizard struct {
+	config  *ModelConfig
+	step    wizardStep
+	history []wizardStep
+
+	inputs   map[wizardStep]*textinput.Model
+	cursors  map[wizardStep]int
+	selected map[string]bool // checked validations of the field being edited
+	ops      map[string]bool // checked operations
+
+	enumDraft  Enum
+	enumIndex  int // index of the enum being edited, -1 when adding
+	fieldDraft Field
+	fieldIndex int // index of the field being edited, -1 when adding
+
+	knownModels []string
+	preview     *preview
+	conflicts   []Conflict
+	savePath    string
+	err         string
+	done        bool
+	aborted     bool
+	width       int
+	height      int
 }
 
-func RunWizard() *ModelConfig {
-	reader := bufio.NewReader(os.Stdin)
-	config := &ModelConfig{}
+// RunWizard asks for a model definition through a full-screen terminal UI
+// and returns the resulting config. It returns ErrAborted if the user quits.
+func RunWizard() (*ModelConfig, error) {
+	return RunWizardWith(&ModelConfig{OutputPath: "./", GenerateHTTP: true})
+}
 
-	fmt.Print("📝 Enter model name (e.g., Order): ")
-	modelName, _ := reader.ReadString('\n')
-	config.ModelName = strings.TrimSpace(modelName)
-	if config.ModelName == "" {
-		fmt.Println("❌ Model name is required.")
-		os.Exit(1)
+// RunWizardWith runs the wizard prefilled with config, e.g. an imported
+// model, so every answer can be confirmed or changed.
+func RunWizardWith(config *ModelConfig) (*ModelConfig, error) {
+	w := newWizard(config)
+
+	final, err := tea.NewProgram(w, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Print("📦 Enter module path (e.g., github.com/your_project): ")
-	modulePath, _ := reader.ReadString('\n')
-	config.ModulePath = strings.TrimSpace(modulePath)
-	if config.ModulePath == "" {
-		fmt.Println("⚠️  Module path is required for imports. Using 'your-module' as fallback.")
-		config.ModulePath = "your-module"
+	w = final.(*wizard)
+	if w.aborted || !w.done {
+		return nil, ErrAborted
+	}
+	if w.savePath != "" {
+		fmt.Printf("💾 Spec saved to %s — regenerate with: gokitgen model -f %s\n", w.savePath, w.savePath)
 	}
+	return w.config, nil
+}
 
-	config.Enums = askEnums(reader)
+func newWizard(config *ModelConfig) *wizard {
+	w := &wizard{
+		config:     config,
+		inputs:     make(map[wizardStep]*textinput.Model),
+		cursors:    make(map[wizardStep]int),
+		selected:   make(map[string]bool),
+		ops:        make(map[string]bool),
+		enumIndex:  -1,
+		fieldIndex: -1,
+	}
 
-	config.Fields = askFields(reader, config.Enums)
+	w.newInput(stepName, "Order", config.ModelName)
+	w.newInput(stepModule, "github.com/your_project", config.ModulePath)
+	w.newInput(stepOutput, "./", config.OutputPath)
+	w.newInput(stepEnumName, "OrderStatus", "")
+	w.newInput(stepEnumValues, "PENDING, CANCELLED", "")
+	w.newInput(stepFieldName, "Amount", "")
+	w.newInput(stepFieldCustomType, "e.g. Ref:Market, []int64 or map[string]float64", "")
+	w.newInput(stepFieldCustomValidation, "e.g. len=3, oneof=a b", "")
+	w.newInput(stepFieldGorm, "e.g. default:0, index, unique", "")
+	w.newInput(stepFieldComment, "e.g. Order side type", "")
+	w.newInput(stepSave, "order.yaml (leave empty to skip)", "")
+
+	for _, op := range AllOperations {
+		w.ops[string(op)] = config.Supports(op)
+	}
+	switch {
+	case config.GenerateHTTP && config.GenerategRPC:
+		w.cursors[stepTransport] = 2
+	case config.GenerategRPC:
+		w.cursors[stepTransport] = 1
+	}
+	w.cursors[stepDatabase] = max(slices.Index(Databases, config.Dialect()), 0)
+	if !config.GenerateTests {
+		w.cursors[stepTests] = 1
+	}
+	return w
+}
 
-	config.GenerateHTTP, config.GenerategRPC = askTransportType(reader)
+func (w *wizard) newInput(step wizardStep, placeholder, value string) {
+	in := textinput.New()
+	in.Placeholder = placeholder
+	in.CharLimit = 256
+	in.SetValue(value)
+	in.Focus()
+	w.inputs[step] = &in
+}
 
-	config.GenerateTests = askGenerateTests(reader)
+func (w *wizard) resetInput(step wizardStep, value string) {
+	w.inputs[step].SetValue(value)
+	w.inputs[step].CursorEnd()
+}
 
-	// you can use ./generated
-	config.OutputPath = "./"
+func (w *wizard) value(step wizardStep) string {
+	return strings.TrimSpace(w.inputs[step].Value())
+}
 
-	return config
+func (w *wizard) Init() tea.Cmd {
+	return textinput.Blink
 }
 
-func askEnums(reader *bufio.Reader) []Enum {
-	var enums []Enum
+func (w *wizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		w.width, w.height = msg.Width, msg.Height
+		if w.preview != nil {
+			w.preview.resize(w.width, w.height)
+		}
+		return w, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			w.aborted = true
+			return w, tea.Quit
+		case "esc":
+			w.err = ""
+			w.back()
+			return w, nil
+		case "enter":
+			if err := w.submit(); err != nil {
+				w.err = err.Error()
+				return w, nil
+			}
+			w.err = ""
+			if w.done {
+				return w, tea.Quit
+			}
+			return w, nil
+		}
 
-	for {
-		fmt.Print("🎨 Add enum? (y/n): ")
-		yn, _ := reader.ReadString('\n')
-		if strings.TrimSpace(strings.ToLower(yn)) != "y" {
-			break
+		if in, ok := w.inputs[w.step]; ok {
+			w.err = ""
+			updated, cmd := in.Update(msg)
+			*in = updated
+			return w, cmd
 		}
+		w.handleKey(msg.String())
+		return w, nil
+	}
 
-		fmt.Print("  Enum name (e.g., OrderStatus): ")
-		name, _ := reader.ReadString('\n')
-		name = strings.TrimSpace(name)
-		if name == "" {
-			fmt.Println("⚠️  Enum name is required. Skipping.")
-			continue
+	if in, ok := w.inputs[w.step]; ok {
+		updated, cmd := in.Update(msg)
+		*in = updated
+		return w, cmd
+	}
+	return w, nil
+}
+
+// handleKey deals with the non-text screens: lists, pickers and toggles.
+func (w *wizard) handleKey(key string) {
+	if w.step == stepPreview {
+		w.preview.handleKey(key)
+		return
+	}
+
+	options := len(w.options())
+
+	switch key {
+	case "up", "k", "shift+tab":
+		if w.cursors[w.step] > 0 {
+			w.cursors[w.step]--
+		}
+		return
+	case "down", "j", "tab":
+		if w.cursors[w.step] < options-1 {
+			w.cursors[w.step]++
 		}
+		return
+	}
 
-		var values []string
-		for {
-			fmt.Print("  Add value (e.g., PENDING) or press Enter to finish: ")
-			val, _ := reader.ReadString('\n')
-			val = strings.TrimSpace(val)
-			if val == "" {
-				break
+	w.err = ""
+	switch w.step {
+	case stepEnums:
+		w.handleListKey(key, len(w.config.Enums), w.startEnum, w.deleteEnum)
+	case stepFields:
+		w.handleListKey(key, len(w.config.Fields), w.startField, w.deleteField)
+	case stepFieldValidation:
+		if key == " " || key == "x" {
+			if opts := w.options(); len(opts) > 0 {
+				opt := opts[w.cursors[w.step]]
+				w.selected[opt] = !w.selected[opt]
 			}
-			values = append(values, val)
 		}
+	case stepOperations:
+		if key == " " || key == "x" {
+			opt := w.options()[w.cursors[w.step]]
+			w.ops[opt] = !w.ops[opt]
+		}
+	}
+}
 
-		if len(values) == 0 {
-			fmt.Println("⚠️  At least one value is required. Skipping enum.")
-			continue
+func (w *wizard) handleListKey(key string, n int, start func(int), remove func(int) error) {
+	switch key {
+	case "a", "+":
+		start(-1)
+	case "e":
+		if n > 0 {
+			start(w.cursors[w.step])
+		}
+	case "d", "delete", "backspace":
+		if n == 0 {
+			return
+		}
+		if err := remove(w.cursors[w.step]); err != nil {
+			w.err = err.Error()
+			return
 		}
+		if w.cursors[w.step] >= n-1 && w.cursors[w.step] > 0 {
+			w.cursors[w.step]--
+		}
+	}
+}
 
-		enums = append(enums, Enum{Name: name, Values: values})
+// options returns the choices of the current picker screen.
+func (w *wizard) options() []string {
+	switch w.step {
+	case stepEnums:
+		return make([]string, len(w.config.Enums))
+	case stepFields:
+		return make([]string, len(w.config.Fields))
+	case stepFieldType:
+		return w.typeOptions()
+	case stepFieldNullable:
+		return []string{"No", "Yes"}
+	case stepFieldValidation:
+		return w.validationOptions()
+	case stepOperations:
+		return operationOptions()
+	case stepTransport:
+		return transportOptions
+	case stepDatabase:
+		return Databases
+	case stepTests:
+		return []string{"Yes", "No"}
 	}
+	return nil
+}
 
-	return enums
+func operationOptions() []string {
+	options := make([]string, len(AllOperations))
+	for i, op := range AllOperations {
+		options[i] = string(op)
+	}
+	return options
 }
 
-func askFields(reader *bufio.Reader, enums []Enum) []Field {
-	var fields []Field
+func (w *wizard) typeOptions() []string {
+	options := slices.Clone(builtinTypes)
+	for _, e := range w.config.Enums {
+		options = append(options, e.Name)
+	}
+	for _, m := range w.knownModels {
+		options = append(options, "Ref:"+m)
+	}
+	return append(options, customTypeOption)
+}
 
-	enumNames := make(map[string]bool)
-	for _, e := range enums {
-		enumNames[e.Name] = true
+func (w *wizard) validationOptions() []string {
+	options := slices.Clone(validationOptions)
+	if w.fieldDraft.TypeIsEnum {
+		for _, e := range w.config.Enums {
+			if e.Name == w.fieldDraft.Type {
+				options = append(options, "oneof="+strings.Join(e.Values, " "))
+			}
+		}
 	}
+	return options
+}
+
+func (w *wizard) goTo(step wizardStep) {
+	w.history = append(w.history, w.step)
+	w.step = step
+}
 
-	for {
-		fmt.Print("➕ Add field? (y/n): ")
-		yn, _ := reader.ReadString('\n')
-		if strings.TrimSpace(strings.ToLower(yn)) != "y" {
-			break
+func (w *wizard) back() {
+	if len(w.history) == 0 {
+		return
+	}
+	w.step = w.history[len(w.history)-1]
+	w.history = w.history[:len(w.history)-1]
+}
+
+// returnTo unwinds history to an earlier screen, used when a sub-form
+// (enum or field) is finished and the user lands back on its list.
+func (w *wizard) returnTo(step wizardStep) {
+	for len(w.history) > 0 {
+		w.back()
+		if w.step == step {
+			return
 		}
+	}
+}
 
-		fmt.Print("  Field name (e.g., Side, Market, Amount): ")
-		name, _ := reader.ReadString('\n')
-		name = strings.TrimSpace(name)
-		if name == "" {
-			fmt.Println("⚠️  Field name is required. Skipping.")
-			continue
+// submit validates the current screen and moves on. Errors are shown inline
+// and keep the user on the same screen.
+func (w *wizard) submit() error {
+	switch w.step {
+	case stepName:
+		name := w.value(stepName)
+		if err := ValidateName("model", name); err != nil {
+			return err
 		}
+		w.config.ModelName = name
+		w.goTo(stepModule)
 
-		fmt.Print("  Field type (e.g., string, int, uint, bool, or enum name like OrderStatus, or Ref:Market for relation): ")
-		typ, _ := reader.ReadString('\n')
-		typ = strings.TrimSpace(typ)
-		if typ == "" {
-			fmt.Println("⚠️  Field type is required. Skipping.")
-			continue
+	case stepModule:
+		module := w.value(stepModule)
+		if module == "" {
+			return fmt.Errorf("module path is required for imports")
+		}
+		if strings.ContainsAny(module, " \t") {
+			return fmt.Errorf("module path must not contain spaces")
+		}
+		w.config.ModulePath = module
+		w.goTo(stepOutput)
+
+	case stepOutput:
+		out := w.value(stepOutput)
+		if out == "" {
+			out = "./"
+		}
+		w.config.OutputPath = out
+		w.knownModels = knownModels(out)
+		for _, f := range w.config.Fields {
+			if f.TypeIsRelation && !slices.Contains(w.knownModels, f.Type) {
+				w.knownModels = append(w.knownModels, f.Type)
+			}
 		}
+		w.goTo(stepEnums)
+
+	case stepEnums:
+		w.goTo(stepFields)
 
-		isEnum := enumNames[typ]
-		isRelation := false
+	case stepEnumName:
+		name := w.value(stepEnumName)
+		if err := ValidateName("enum", name); err != nil {
+			return err
+		}
+		for i, e := range w.config.Enums {
+			if e.Name == name && i != w.enumIndex {
+				return fmt.Errorf("enum %s already exists", name)
+			}
+		}
+		w.enumDraft.Name = name
+		w.goTo(stepEnumValues)
 
-		if strings.HasPrefix(typ, "Ref:") {
-			isRelation = true
-			typ = strings.TrimPrefix(typ, "Ref:")
+	case stepEnumValues:
+		enum, err := ParseEnum(w.enumDraft.Name + "=" + w.value(stepEnumValues))
+		if err != nil {
+			return err
 		}
+		w.saveEnum(enum)
+		w.returnTo(stepEnums)
 
-		fmt.Print("  Nullable? (y/n): ")
-		nullable, _ := reader.ReadString('\n')
-		isNullable := strings.TrimSpace(strings.ToLower(nullable)) == "y"
+	case stepFields:
+		w.goTo(stepOperations)
 
-		var validations []string
-		for {
-			fmt.Print("  Add validation? (e.g., required, email, min=1, or press Enter to skip): ")
-			val, _ := reader.ReadString('\n')
-			val = strings.TrimSpace(val)
-			if val == "" {
-				break
+	case stepOperations:
+		var ops []Operation
+		for _, op := range AllOperations {
+			if w.ops[string(op)] {
+				ops = append(ops, op)
 			}
-			validations = append(validations, val)
 		}
+		if len(ops) == 0 {
+			return fmt.Errorf("select at least one operation")
+		}
+		if len(ops) == len(AllOperations) {
+			ops = nil
+		}
+		w.config.Operations = ops
+		w.goTo(stepTransport)
 
-		var gormTag string
-		if !isRelation && !isEnum {
-			fmt.Print("  Add GORM tag? (e.g., default:0, index, unique, or press Enter to skip): ")
-			tag, _ := reader.ReadString('\n')
-			gormTag = strings.TrimSpace(tag)
+	case stepFieldName:
+		name := w.value(stepFieldName)
+		if err := ValidateName("field", name); err != nil {
+			return err
+		}
+		for i, f := range w.config.Fields {
+			if f.Name == name && i != w.fieldIndex {
+				return fmt.Errorf("field %s already exists", name)
+			}
 		}
+		w.fieldDraft.Name = name
+		w.goTo(stepFieldType)
+
+	case stepFieldType:
+		choice := w.typeOptions()[w.cursors[stepFieldType]]
+		if choice == customTypeOption {
+			w.goTo(stepFieldCustomType)
+			return nil
+		}
+		w.setFieldType(choice)
+		w.goTo(stepFieldNullable)
+
+	case stepFieldCustomType:
+		typ := w.value(stepFieldCustomType)
+		if typ == "" || typ == "Ref:" {
+			return fmt.Errorf("field type is required")
+		}
+		if resolved := NewField(w.fieldDraft.Name, typ, w.config.Enums); !resolved.TypeIsEnum && !resolved.TypeIsRelation {
+			if err := ValidateType(typ); err != nil {
+				return err
+			}
+		}
+		w.setFieldType(typ)
+		w.goTo(stepFieldNullable)
+
+	case stepFieldNullable:
+		w.fieldDraft.IsNullable = w.cursors[stepFieldNullable] == 1
+		w.goTo(stepFieldValidation)
 
-		fmt.Print("  Add comment? (e.g., Order side type, or press Enter to skip): ")
-		comment, _ := reader.ReadString('\n')
-		comment = strings.TrimSpace(comment)
+	case stepFieldValidation:
+		w.goTo(stepFieldCustomValidation)
 
-		fields = append(fields, Field{
-			Name:           name,
-			Type:           typ,
-			TypeIsEnum:     isEnum,
-			TypeIsRelation: isRelation,
-			IsNullable:     isNullable,
-			Validation:     validations,
-			GormTag:        gormTag,
-			Comment:        comment,
-		})
+	case stepFieldCustomValidation:
+		w.fieldDraft.Validation = nil
+		for _, opt := range w.validationOptions() {
+			if w.selected[opt] {
+				w.fieldDraft.Validation = append(w.fieldDraft.Validation, opt)
+			}
+		}
+		for _, v := range strings.Split(w.value(stepFieldCustomValidation), ",") {
+			if v = strings.TrimSpace(v); v != "" && !slices.Contains(w.fieldDraft.Validation, v) {
+				w.fieldDraft.Validation = append(w.fieldDraft.Validation, v)
+			}
+		}
+		if w.fieldDraft.TypeIsEnum || w.fieldDraft.TypeIsRelation {
+			w.fieldDraft.GormTag = ""
+			w.goTo(stepFieldComment)
+			return nil
+		}
+		w.goTo(stepFieldGorm)
+
+	case stepFieldGorm:
+		w.fieldDraft.GormTag = w.value(stepFieldGorm)
+		w.goTo(stepFieldComment)
+
+	case stepFieldComment:
+		w.fieldDraft.Comment = w.value(stepFieldComment)
+		w.saveField()
+		w.returnTo(stepFields)
+
+	case stepTransport:
+		choice := w.cursors[stepTransport]
+		w.config.GenerateHTTP = choice == 0 || choice == 2
+		w.config.GenerategRPC = choice == 1 || choice == 2
+		w.goTo(stepDatabase)
+
+	case stepDatabase:
+		w.config.Database = Databases[w.cursors[stepDatabase]]
+		w.goTo(stepTests)
+
+	case stepTests:
+		w.config.GenerateTests = w.cursors[stepTests] == 0
+		w.goTo(stepSave)
+
+	case stepSave:
+		path := w.value(stepSave)
+		if path != "" {
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				return fmt.Errorf("spec file must end in .yaml, .yml or .json")
+			}
+		}
+		if err := w.config.Validate(); err != nil {
+			return err
+		}
+		w.savePath = path
+		w.preview = newPreview(w.config, w.width, w.height)
+		w.goTo(stepPreview)
+
+	case stepPreview:
+		if w.preview.err != nil {
+			return w.preview.err
+		}
+		_, conflicts, err := NewGenerator(DiskOutput{}).Plan(w.config)
+		if err != nil {
+			return err
+		}
+		w.conflicts = conflicts
+		w.goTo(stepConfirm)
+
+	case stepConfirm:
+		if err := w.config.Validate(); err != nil {
+			return err
+		}
+		if w.savePath != "" {
+			if err := SaveSpec(w.savePath, w.config); err != nil {
+				return fmt.Errorf("could not save spec: %w", err)
+			}
+		}
+		w.done = true
 	}
+	return nil
+}
 
-	return fields
+func (w *wizard) setFieldType(typ string) {
+	resolved := NewField(w.fieldDraft.Name, typ, w.config.Enums)
+	w.fieldDraft.Type = resolved.Type
+	w.fieldDraft.TypeIsEnum = resolved.TypeIsEnum
+	w.fieldDraft.TypeIsRelation = resolved.TypeIsRelation
 }
 
-func askTransportType(reader *bufio.Reader) (bool, bool) {
-	fmt.Print("🌐 Generate API for (1=HTTP, 2=gRPC, 3=Both): ")
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(choice)
+// startEnum opens the enum form, either empty (index -1) or for editing.
+func (w *wizard) startEnum(index int) {
+	w.enumIndex = index
+	w.enumDraft = Enum{}
+	if index >= 0 {
+		w.enumDraft = w.config.Enums[index]
+	}
+	w.resetInput(stepEnumName, w.enumDraft.Name)
+	w.resetInput(stepEnumValues, strings.Join(w.enumDraft.Values, ", "))
+	w.goTo(stepEnumName)
+}
+
+func (w *wizard) saveEnum(enum Enum) {
+	if w.enumIndex < 0 {
+		w.config.Enums = append(w.config.Enums, enum)
+		w.cursors[stepEnums] = len(w.config.Enums) - 1
+		return
+	}
 
-	switch choice {
-	case "1":
-		return true, false
-	case "2":
-		return false, true
-	case "3":
-		return true, true
-	default:
-		fmt.Println("⚠️  Invalid choice. Defaulting to HTTP.")
-		return true, false
+	// Keep fields pointing at a renamed enum.
+	old := w.config.Enums[w.enumIndex].Name
+	for i, f := range w.config.Fields {
+		if f.TypeIsEnum && f.Type == old {
+			w.config.Fields[i].Type = enum.Name
+		}
 	}
+	w.config.Enums[w.enumIndex] = enum
 }
 
-func askGenerateTests(reader *bufio.Reader) bool {
-	fmt.Print("🧪 Generate tests? (y/n): ")
-	yn, _ := reader.ReadString('\n')
-	return strings.TrimSpace(strings.ToLower(yn)) == "y"
-}
\ No newline at end of file
+func (w *wizard) deleteEnum(index int) error {
+	name := w.config.Enums[index].Name
+	for _, f := range w.config.Fields {
+		if f.TypeIsEnum && f.Type == name {
+			return fmt.Errorf("enum %s is used by field %s", name, f.Name)
+		}
+	}
+	w.config.Enums = slices.Delete(w.config.Enums, index, index+1)
+	return nil
+}
+
+// startField opens the field form, either empty (index -1) or for editing.
+func (w *wizard) startField(index int) {
+	w.fieldIndex = index
+	w.fieldDraft = Field{}
+	if index >= 0 {
+		w.fieldDraft = w.config.Fields[index]
+	}
+	f := w.fieldDraft
+
+	w.resetInput(stepFieldName, f.Name)
+	w.resetInput(stepFieldGorm, f.GormTag)
+	w.resetInput(stepFieldComment, f.Comment)
+
+	typ := f.Type
+	if f.TypeIsRelation {
+		typ = "Ref:" + typ
+	}
+	options := w.typeOptions()
+	w.cursors[stepFieldType] = 0
+	w.resetInput(stepFieldCustomType, "")
+	if typ != "" {
+		if i := slices.Index(options, typ); i >= 0 {
+			w.cursors[stepFieldType] = i
+		} else {
+			w.cursors[stepFieldType] = len(options) - 1
+			w.resetInput(stepFieldCustomType, typ)
+		}
+	}
+
+	w.cursors[stepFieldNullable] = 0
+	if f.IsNullable {
+		w.cursors[stepFieldNullable] = 1
+	}
+
+	w.cursors[stepFieldValidation] = 0
+	w.selected = make(map[string]bool)
+	var custom []string
+	for _, v := range f.Validation {
+		if slices.Contains(validationOptions, v) || strings.HasPrefix(v, "oneof=") {
+			w.selected[v] = true
+		} else {
+			custom = append(custom, v)
+		}
+	}
+	w.resetInput(stepFieldCustomValidation, strings.Join(custom, ", "))
+
+	w.goTo(stepFieldName)
+}
+
+func (w *wizard) saveField() {
+	if w.fieldIndex < 0 {
+		w.config.Fields = append(w.config.Fields, w.fieldDraft)
+		w.cursors[stepFields] = len(w.config.Fields) - 1
+		return
+	}
+	w.config.Fields[w.fieldIndex] = w.fieldDraft
+}
+
+func (w *wizard) deleteField(index int) error {
+	w.config.Fields = slices.Delete(w.config.Fields, index, index+1)
+	return nil
+}
+
+// knownModels lists the struct types already generated under
+// internal/models so they can be offered as relation targets.
+func knownModels(outputPath string) []string {
+	files, _ := filepath.Glob(filepath.Join(outputPath, "internal", "models", "*.go"))
+
+	var models []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.StructType); ok && ts.Name.IsExported() {
+					models = append(models, ts.Name.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(models)
+	return slices.Compact(models)
+}
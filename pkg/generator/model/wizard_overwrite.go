@@ -0,0 +1,107 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var overwriteOptions = []string{"Overwrite all", "Skip existing files", "Cancel"}
+
+// overwritePrompt lists conflicting files and asks what to do about them.
+type overwritePrompt struct {
+	conflicts []Conflict
+	cursor    int
+	choice    int
+	done      bool
+}
+
+// ConfirmOverwrite asks in the terminal whether existing files may be
+// overwritten. It fits Generator.Confirm and returns ErrAborted on cancel.
+func ConfirmOverwrite(conflicts []Conflict) (OverwritePolicy, error) {
+	final, err := tea.NewProgram(&overwritePrompt{conflicts: conflicts, choice: -1}).Run()
+	if err != nil {
+		return OverwriteAsk, err
+	}
+
+	switch final.(*overwritePrompt).choice {
+	case 0:
+		return OverwriteForce, nil
+	case 1:
+		return OverwriteSkip, nil
+	default:
+		return OverwriteAsk, ErrAborted
+	}
+}
+
+func (p *overwritePrompt) Init() tea.Cmd {
+	return nil
+}
+
+func (p *overwritePrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch key.String() {
+	case "up", "k", "shift+tab":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j", "tab":
+		if p.cursor < len(overwriteOptions)-1 {
+			p.cursor++
+		}
+	case "y":
+		p.choice, p.done = 0, true
+	case "s":
+		p.choice, p.done = 1, true
+	case "n", "q", "esc", "ctrl+c":
+		p.choice, p.done = 2, true
+	case "enter":
+		p.choice, p.done = p.cursor, true
+	}
+	if p.done {
+		return p, tea.Quit
+	}
+	return p, nil
+}
+
+func (p *overwritePrompt) View() string {
+	if p.done {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(wizardErrorStyle.Render(fmt.Sprintf("⚠️  %d generated file(s) already exist with different content:", len(p.conflicts))))
+	b.WriteString("\n\n")
+	b.WriteString(ConflictReport(p.conflicts))
+	b.WriteString("\n\n")
+	for i, opt := range overwriteOptions {
+		if i == p.cursor {
+			b.WriteString(wizardCursorStyle.Render("› "+opt) + "\n")
+		} else {
+			b.WriteString("  " + opt + "\n")
+		}
+	}
+	b.WriteString("\n" + wizardDimStyle.Render("↑/↓ move • enter select • y overwrite • s skip • n cancel"))
+	return b.String()
+}
+
+// ConflictReport renders one line per conflicting file with its line changes.
+func ConflictReport(conflicts []Conflict) string {
+	width := 0
+	for _, c := range conflicts {
+		width = max(width, len(c.Path))
+	}
+
+	lines := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		lines[i] = fmt.Sprintf("  %-*s  %s %s", width, c.Path,
+			wizardStageStyle.Render(fmt.Sprintf("+%d", c.Added)),
+			wizardErrorStyle.Render(fmt.Sprintf("-%d", c.Removed)))
+	}
+	return strings.Join(lines, "\n")
+}
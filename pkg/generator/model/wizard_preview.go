@@ -0,0 +1,271 @@
+package model
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	previewBoxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#767676"))
+
+	previewDirStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FAFFF"))
+	previewGutterStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#585858"))
+	previewKeywordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Bold(true)
+	previewStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#A8CC8C"))
+	previewNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#E5C07B"))
+	previewCommentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676")).Italic(true)
+	previewTypeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#56B6C2"))
+)
+
+const previewTreeWidth = 36
+
+var protoKeywords = map[string]bool{
+	"syntax": true, "package": true, "import": true, "option": true, "message": true,
+	"enum": true, "service": true, "rpc": true, "returns": true, "repeated": true,
+	"optional": true, "map": true, "oneof": true, "reserved": true,
+}
+
+var builtinTypeNames = map[string]bool{
+	"string": true, "bool": true, "byte": true, "rune": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "bytes": true, "double": true, "float": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+}
+
+type previewEntry struct {
+	label string
+	file  int // index into files, -1 for a directory line
+}
+
+// preview renders every file of the current config in memory, with the
+// user regions of existing files merged in, and lets the user browse them
+// before anything is written.
+type preview struct {
+	files    []GeneratedFile
+	entries  []previewEntry
+	selected int // index into entries, always pointing at a file
+	viewport viewport.Model
+	err      error
+}
+
+func newPreview(config *ModelConfig, width, height int) *preview {
+	p := &preview{}
+
+	p.files, _, p.err = NewGenerator(DiskOutput{}).Plan(config)
+	if p.err != nil {
+		return p
+	}
+	sort.Slice(p.files, func(i, j int) bool { return p.files[i].Path < p.files[j].Path })
+
+	var prevDirs []string
+	for i, f := range p.files {
+		rel, err := filepath.Rel(config.OutputPath, f.Path)
+		if err != nil {
+			rel = f.Path
+		}
+		dirs := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+		if dirs[0] == "." {
+			dirs = nil
+		}
+
+		common := 0
+		for common < len(dirs) && common < len(prevDirs) && dirs[common] == prevDirs[common] {
+			common++
+		}
+		for d := common; d < len(dirs); d++ {
+			p.entries = append(p.entries, previewEntry{label: strings.Repeat("  ", d) + dirs[d] + "/", file: -1})
+		}
+		p.entries = append(p.entries, previewEntry{label: strings.Repeat("  ", len(dirs)) + filepath.Base(rel), file: i})
+		prevDirs = dirs
+	}
+
+	p.viewport = viewport.New(0, 0)
+	p.resize(width, height)
+	p.selected = -1
+	p.move(1)
+	return p
+}
+
+func (p *preview) resize(width, height int) {
+	if width == 0 {
+		width = 120
+	}
+	if height == 0 {
+		height = 32
+	}
+	p.viewport.Width = max(20, width-previewTreeWidth-10)
+	p.viewport.Height = max(5, height-12)
+}
+
+// move selects the next (dir > 0) or previous file in the tree, skipping
+// directory lines.
+func (p *preview) move(dir int) {
+	for i := p.selected + dir; i >= 0 && i < len(p.entries); i += dir {
+		if p.entries[i].file >= 0 {
+			p.selected = i
+			p.show()
+			return
+		}
+	}
+}
+
+func (p *preview) show() {
+	f := p.files[p.entries[p.selected].file]
+	p.viewport.SetContent(highlight(f.Path, f.Content))
+	p.viewport.GotoTop()
+}
+
+func (p *preview) handleKey(key string) {
+	switch key {
+	case "up", "k", "shift+tab":
+		p.move(-1)
+	case "down", "j", "tab":
+		p.move(1)
+	case "pgdown", "ctrl+d", " ":
+		p.viewport.HalfPageDown()
+	case "pgup", "ctrl+u":
+		p.viewport.HalfPageUp()
+	case "J", "ctrl+n":
+		p.viewport.ScrollDown(1)
+	case "K", "ctrl+p":
+		p.viewport.ScrollUp(1)
+	case "home", "g":
+		p.viewport.GotoTop()
+	case "end", "G":
+		p.viewport.GotoBottom()
+	}
+}
+
+func (p *preview) View() string {
+	if p.err != nil {
+		return wizardErrorStyle.Render("❌ Could not render templates: "+p.err.Error()) +
+			"\n\n" + wizardDimStyle.Render("Go back and fix the model, then return to the preview.")
+	}
+
+	if len(p.files) == 0 {
+		return wizardDimStyle.Render("Nothing to generate.")
+	}
+
+	// Keep the selected file visible when the tree is taller than the pane.
+	first := 0
+	if len(p.entries) > p.viewport.Height {
+		first = min(max(0, p.selected-p.viewport.Height/2), len(p.entries)-p.viewport.Height)
+	}
+	last := min(len(p.entries), first+p.viewport.Height)
+
+	var tree strings.Builder
+	for i := first; i < last; i++ {
+		e := p.entries[i]
+		name := strings.TrimLeft(e.label, " ")
+		indent := e.label[:len(e.label)-len(name)]
+		switch {
+		case i == p.selected:
+			tree.WriteString(indent + wizardCursorStyle.Render("› "+name) + "\n")
+		case e.file < 0:
+			tree.WriteString(indent + "  " + previewDirStyle.Render(name) + "\n")
+		default:
+			tree.WriteString(indent + "  " + name + "\n")
+		}
+	}
+
+	// One extra line lines the tree up with the file title on the right.
+	treeBox := previewBoxStyle.
+		Width(previewTreeWidth).
+		Height(p.viewport.Height + 1).
+		Render(strings.TrimRight(tree.String(), "\n"))
+
+	f := p.files[p.entries[p.selected].file]
+	title := fmt.Sprintf("%s  %s", f.Path, wizardDimStyle.Render(fmt.Sprintf("%d bytes • %3.f%%", len(f.Content), p.viewport.ScrollPercent()*100)))
+	contentBox := previewBoxStyle.Render(p.viewport.View())
+
+	right := lipgloss.JoinVertical(lipgloss.Left, " "+title, contentBox)
+	return lipgloss.JoinHorizontal(lipgloss.Top, treeBox, " ", right)
+}
+
+// highlight colours Go and proto sources token by token and prefixes each
+// line with its number. Both languages tokenize well enough with go/scanner.
+func highlight(path string, src []byte) string {
+	isProto := strings.HasSuffix(path, ".proto")
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(path, fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+
+	var out strings.Builder
+	offset := 0
+	emit := func(text string, style *lipgloss.Style) {
+		if style == nil {
+			out.WriteString(text)
+			return
+		}
+		// Style every line on its own so multi-line comments and raw
+		// strings do not break the gutter.
+		for i, part := range strings.Split(text, "\n") {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			if part != "" {
+				out.WriteString(style.Render(part))
+			}
+		}
+	}
+
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // inserted automatically, not in the source
+		}
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		start := file.Offset(pos)
+		end := start + len(text)
+		if start < offset {
+			continue
+		}
+		if end > len(src) || string(src[start:end]) != text {
+			continue
+		}
+
+		emit(string(src[offset:start]), nil)
+
+		var style *lipgloss.Style
+		switch {
+		case tok == token.COMMENT:
+			style = &previewCommentStyle
+		case tok == token.STRING || tok == token.CHAR:
+			style = &previewStringStyle
+		case tok == token.INT || tok == token.FLOAT:
+			style = &previewNumberStyle
+		case tok.IsKeyword() || isProto && tok == token.IDENT && protoKeywords[lit]:
+			style = &previewKeywordStyle
+		case tok == token.IDENT && builtinTypeNames[lit]:
+			style = &previewTypeStyle
+		}
+		emit(text, style)
+		offset = end
+	}
+	emit(string(src[offset:]), nil)
+
+	lines := strings.Split(out.String(), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	for i, line := range lines {
+		lines[i] = previewGutterStyle.Render(fmt.Sprintf("%*d ", width, i+1)) + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,268 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	wizardTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5F87")).
+				Bold(true).
+				Padding(0, 1)
+
+	wizardPromptStyle = lipgloss.NewStyle().Bold(true)
+
+	wizardCursorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5F87")).
+				Bold(true)
+
+	wizardDimStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676"))
+
+	wizardErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF4672"))
+
+	wizardStageStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+
+	wizardDocStyle = lipgloss.NewStyle().Margin(1, 2)
+)
+
+var wizardStages = []string{"Model", "Module", "Output", "Enums", "Fields", "Operations", "Transport", "Database", "Tests", "Save", "Preview", "Confirm"}
+
+func (s wizardStep) stage() int {
+	switch {
+	case s <= stepOutput:
+		return int(s)
+	case s <= stepEnumValues:
+		return 3
+	case s <= stepFieldComment:
+		return 4
+	default:
+		return int(s-stepOperations) + 5
+	}
+}
+
+func (w *wizard) View() string {
+	if w.done || w.aborted {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(wizardTitleStyle.Render("✨ Go Kit Generator — New Model"))
+	b.WriteString("\n")
+	b.WriteString(w.stagesView())
+	b.WriteString("\n\n")
+	b.WriteString(w.bodyView())
+	b.WriteString("\n")
+
+	if w.err != "" {
+		b.WriteString("\n" + wizardErrorStyle.Render("❌ "+w.err) + "\n")
+	}
+	b.WriteString("\n" + wizardDimStyle.Render(w.helpView()))
+	return wizardDocStyle.Render(b.String())
+}
+
+func (w *wizard) stagesView() string {
+	current := w.step.stage()
+	parts := make([]string, len(wizardStages))
+	for i, name := range wizardStages {
+		switch {
+		case i == current:
+			parts[i] = wizardCursorStyle.Render(name)
+		case i < current:
+			parts[i] = wizardStageStyle.Render(name)
+		default:
+			parts[i] = wizardDimStyle.Render(name)
+		}
+	}
+	return " " + strings.Join(parts, wizardDimStyle.Render(" › "))
+}
+
+func (w *wizard) bodyView() string {
+	switch w.step {
+	case stepName:
+		return w.inputView("📝 Model name")
+	case stepModule:
+		return w.inputView("📦 Module path")
+	case stepOutput:
+		return w.inputView("📂 Output directory")
+	case stepEnums:
+		return w.listView("🎨 Enums", w.enumLines(), "No enums yet — press a to add one.")
+	case stepEnumName:
+		return w.inputView("🎨 Enum name")
+	case stepEnumValues:
+		return w.inputView(fmt.Sprintf("🎨 Values of %s (comma separated)", w.enumDraft.Name))
+	case stepFields:
+		return w.listView(fmt.Sprintf("➕ Fields of %s", w.config.ModelName), w.fieldLines(), "No fields yet — press a to add one.")
+	case stepFieldName:
+		return w.inputView("➕ Field name")
+	case stepFieldType:
+		return w.pickerView(fmt.Sprintf("🔤 Type of %s", w.fieldDraft.Name), w.typeOptions(), nil)
+	case stepFieldCustomType:
+		return w.inputView(fmt.Sprintf("🔤 Type of %s (Go type, enum name or Ref:Model)", w.fieldDraft.Name))
+	case stepFieldNullable:
+		return w.pickerView(fmt.Sprintf("❔ Is %s nullable?", w.fieldDraft.Name), w.options(), nil)
+	case stepFieldValidation:
+		return w.pickerView(fmt.Sprintf("✅ Validations of %s", w.fieldDraft.Name), w.options(), w.selected)
+	case stepFieldCustomValidation:
+		return w.inputView("✅ Extra validations (comma separated, optional)")
+	case stepFieldGorm:
+		return w.inputView("🗃️  GORM tag (optional)")
+	case stepFieldComment:
+		return w.inputView("💬 Comment (optional)")
+	case stepOperations:
+		return w.pickerView("⚙️  Operations", w.options(), w.ops)
+	case stepTransport:
+		return w.pickerView("🌐 Generate API for", transportOptions, nil)
+	case stepDatabase:
+		return w.pickerView("🗄️  Database", Databases, nil)
+	case stepTests:
+		return w.pickerView("🧪 Generate tests?", w.options(), nil)
+	case stepSave:
+		return w.inputView("💾 Save answers as a spec file")
+	case stepPreview:
+		return w.preview.View()
+	case stepConfirm:
+		return w.summaryView()
+	}
+	return ""
+}
+
+func (w *wizard) inputView(prompt string) string {
+	return wizardPromptStyle.Render(prompt) + "\n\n" + w.inputs[w.step].View()
+}
+
+func (w *wizard) pickerView(prompt string, options []string, checked map[string]bool) string {
+	var b strings.Builder
+	b.WriteString(wizardPromptStyle.Render(prompt) + "\n\n")
+	for i, opt := range options {
+		line := opt
+		if checked != nil {
+			box := "[ ] "
+			if checked[opt] {
+				box = "[x] "
+			}
+			line = box + opt
+		}
+		if i == w.cursors[w.step] {
+			b.WriteString(wizardCursorStyle.Render("› "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (w *wizard) listView(prompt string, lines []string, empty string) string {
+	if len(lines) == 0 {
+		return wizardPromptStyle.Render(prompt) + "\n\n" + wizardDimStyle.Render(empty)
+	}
+	return w.pickerView(prompt, lines, nil)
+}
+
+func (w *wizard) enumLines() []string {
+	lines := make([]string, len(w.config.Enums))
+	for i, e := range w.config.Enums {
+		lines[i] = fmt.Sprintf("%s  %s", e.Name, wizardDimStyle.Render(strings.Join(e.Values, ", ")))
+	}
+	return lines
+}
+
+func (w *wizard) fieldLines() []string {
+	lines := make([]string, len(w.config.Fields))
+	for i, f := range w.config.Fields {
+		lines[i] = fmt.Sprintf("%s  %s", f.Name, wizardDimStyle.Render(describeField(f)))
+	}
+	return lines
+}
+
+func describeField(f Field) string {
+	typ := f.Type
+	switch {
+	case f.TypeIsRelation:
+		typ = "Ref:" + typ
+	case f.TypeIsEnum:
+		typ += " (enum)"
+	}
+	if f.IsNullable {
+		typ = "*" + typ
+	}
+
+	parts := []string{typ}
+	if len(f.Validation) > 0 {
+		parts = append(parts, "validate:"+strings.Join(f.Validation, ","))
+	}
+	if f.GormTag != "" {
+		parts = append(parts, "gorm:"+f.GormTag)
+	}
+	if f.Comment != "" {
+		parts = append(parts, "// "+f.Comment)
+	}
+	return strings.Join(parts, "  ")
+}
+
+func (w *wizard) summaryView() string {
+	c := w.config
+	var b strings.Builder
+	b.WriteString(wizardPromptStyle.Render("🚀 Ready to generate") + "\n\n")
+	fmt.Fprintf(&b, "  Model:      %s\n", c.ModelName)
+	fmt.Fprintf(&b, "  Module:     %s\n", c.ModulePath)
+	fmt.Fprintf(&b, "  Output:     %s\n", c.OutputPath)
+
+	b.WriteString("  Enums:      ")
+	if len(c.Enums) == 0 {
+		b.WriteString(wizardDimStyle.Render("none"))
+	}
+	for i, e := range c.Enums {
+		if i > 0 {
+			b.WriteString("\n              ")
+		}
+		fmt.Fprintf(&b, "%s = %s", e.Name, strings.Join(e.Values, ", "))
+	}
+
+	b.WriteString("\n  Fields:     ")
+	if len(c.Fields) == 0 {
+		b.WriteString(wizardDimStyle.Render("none"))
+	}
+	for i, f := range c.Fields {
+		if i > 0 {
+			b.WriteString("\n              ")
+		}
+		fmt.Fprintf(&b, "%s %s", f.Name, describeField(f))
+	}
+
+	ops := make([]string, 0, len(AllOperations))
+	for _, op := range c.SelectedOperations() {
+		ops = append(ops, string(op))
+	}
+	fmt.Fprintf(&b, "\n  Operations: %s\n", strings.Join(ops, ", "))
+	fmt.Fprintf(&b, "  Transport:  %s\n", transportOptions[w.cursors[stepTransport]])
+	fmt.Fprintf(&b, "  Database:   %s\n", c.Dialect())
+	fmt.Fprintf(&b, "  Tests:      %t\n", c.GenerateTests)
+	if w.savePath != "" {
+		fmt.Fprintf(&b, "  Spec file:  %s\n", w.savePath)
+	}
+	if len(w.conflicts) > 0 {
+		b.WriteString("\n" + wizardErrorStyle.Render(fmt.Sprintf("⚠️  %d file(s) already exist and would change:", len(w.conflicts))) + "\n")
+		b.WriteString(ConflictReport(w.conflicts) + "\n")
+		b.WriteString(wizardDimStyle.Render("You will be asked whether to overwrite or skip them.") + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (w *wizard) helpView() string {
+	switch w.step {
+	case stepEnums, stepFields:
+		return "↑/↓ move • a add • e edit • d delete • enter continue • esc back • ctrl+c quit"
+	case stepFieldValidation, stepOperations:
+		return "↑/↓ move • space toggle • enter continue • esc back • ctrl+c quit"
+	case stepFieldType, stepFieldNullable, stepTransport, stepDatabase, stepTests:
+		return "↑/↓ move • enter select • esc back • ctrl+c quit"
+	case stepPreview:
+		return "↑/↓ file • pgup/pgdn scroll • enter continue • esc back to fix the model • ctrl+c quit"
+	case stepConfirm:
+		return "enter generate • esc back • ctrl+c quit"
+	}
+	return "enter continue • esc back • ctrl+c quit"
+}